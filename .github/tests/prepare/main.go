@@ -11,9 +11,12 @@ var fail = false
 
 func main() {
 	// Write an output for the second action.
-	goaction.Output("out", "message", "output of first action")
+	err := goaction.Output("out", "message", "output of first action")
+	if err != nil {
+		log.Fatal(err)
+	}
 	// Set an environment variable for the second action.
-	err := goaction.Setenv("set", "set")
+	err = goaction.Setenv("set", "set")
 	if err != nil {
 		log.Fatal(err)
 	}
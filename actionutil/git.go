@@ -65,6 +65,9 @@ func GitDiffAll() ([]Diff, error) {
 // GitCommitPush commits and pushes a list of files.
 func GitCommitPush(paths []string, message string) error {
 	branch := goaction.Branch()
+	if branch == "" {
+		return fmt.Errorf("can't push, not running on a branch (ref: %q)", goaction.Ref)
+	}
 
 	// Reset git if there where any staged files.
 	err := git("reset").ToStdout()
@@ -0,0 +1,200 @@
+// Package annotate converts the output of Go tools to Github action annotations. The errors then
+// show up inline in the "Files changed" view of pull requests.
+//
+// The following outputs are supported:
+//
+// * `go build` and `go vet`, and any other tool that outputs `file:line:col: message` lines, such
+// as staticcheck.
+//
+// * `go vet -json`.
+//
+// * `gofmt -l`.
+//
+// For example:
+//
+//	diags, err := annotate.Parse(vetOutput)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	annotate.Error(diags)
+package annotate
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/posener/goaction/log"
+)
+
+// Diagnostic is a message about a location in a file.
+type Diagnostic struct {
+	// Title is the optional title of the diagnostic, for example the go vet analyzer name.
+	Title string
+	Start token.Position
+	// End is the optional end position of the diagnostic.
+	End     token.Position
+	Message string
+}
+
+var (
+	// A diagnostic line: "file.go:line:col: message" or "file.go:line: message".
+	reDiagnostic = regexp.MustCompile(`^(?:vet: )?(.+?\.go):(\d+)(?::(\d+))?: (.*)$`)
+	// A file path that is printed by gofmt -l.
+	reFile = regexp.MustCompile(`^[^\s:]*\.go$`)
+	// A position in go vet -json output: "file.go:line:col".
+	rePosn = regexp.MustCompile(`^(.+?\.go):(\d+)(?::(\d+))?$`)
+)
+
+// Parse parses diagnostics from the output of Go tools. Lines that are not diagnostics are
+// ignored. When the diagnostics are logged, the log package makes their file paths relative to the
+// Github workspace directory.
+func Parse(r io.Reader) ([]Diagnostic, error) {
+	var (
+		diags []Diagnostic
+		// Lines of go vet -json output.
+		jsonLines []string
+	)
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		switch {
+		case jsonLines != nil || line == "{":
+			// JSON object of go vet -json output, which ends with a closing brace in the first
+			// column.
+			jsonLines = append(jsonLines, line)
+			if line != "}" {
+				continue
+			}
+			vetDiags, err := parseVetJSON(strings.Join(jsonLines, "\n"))
+			if err != nil {
+				return nil, err
+			}
+			diags = append(diags, vetDiags...)
+			jsonLines = nil
+		case strings.HasPrefix(line, "\t") && len(diags) > 0:
+			// Continuation of the previous diagnostic message.
+			diags[len(diags)-1].Message += "\n" + strings.TrimSpace(line)
+		case reDiagnostic.MatchString(line):
+			m := reDiagnostic.FindStringSubmatch(line)
+			diags = append(diags, Diagnostic{
+				Start:   position(m[1], m[2], m[3]),
+				Message: m[4],
+			})
+		case reFile.MatchString(line):
+			diags = append(diags, Diagnostic{
+				Title:   "gofmt",
+				Start:   token.Position{Filename: line},
+				Message: "File is not formatted, run gofmt.",
+			})
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if jsonLines != nil {
+		return nil, fmt.Errorf("unterminated JSON output")
+	}
+	return diags, nil
+}
+
+// vetDiagnostic is a diagnostic in go vet -json output.
+type vetDiagnostic struct {
+	Posn    string `json:"posn"`
+	End     string `json:"end"`
+	Message string `json:"message"`
+}
+
+// vetError is an error of an analyzer in go vet -json output.
+type vetError struct {
+	Error string `json:"error"`
+}
+
+// parseVetJSON parses go vet -json output. The output maps package to analyzer to a list of
+// diagnostics, or to an error object. An analyzer error is returned as a diagnostic without a
+// position, such that the failure is not silently ignored.
+func parseVetJSON(data string) ([]Diagnostic, error) {
+	var pkgs map[string]map[string]json.RawMessage
+	err := json.Unmarshal([]byte(data), &pkgs)
+	if err != nil {
+		return nil, fmt.Errorf("parsing go vet JSON output: %s", err)
+	}
+	var diags []Diagnostic
+	for _, analyzers := range pkgs {
+		for analyzer, raw := range analyzers {
+			var vetDiags []vetDiagnostic
+			if json.Unmarshal(raw, &vetDiags) != nil {
+				// Not a list of diagnostics.
+				var vetErr vetError
+				if json.Unmarshal(raw, &vetErr) == nil && vetErr.Error != "" {
+					diags = append(diags, Diagnostic{Title: analyzer, Message: vetErr.Error})
+				}
+				continue
+			}
+			for _, d := range vetDiags {
+				diag := Diagnostic{Title: analyzer, Message: d.Message}
+				if m := rePosn.FindStringSubmatch(d.Posn); m != nil {
+					diag.Start = position(m[1], m[2], m[3])
+				}
+				if m := rePosn.FindStringSubmatch(d.End); m != nil {
+					diag.End = position(m[1], m[2], m[3])
+				}
+				// A range that ends where it starts is not a range.
+				if diag.End == diag.Start {
+					diag.End = token.Position{}
+				}
+				diags = append(diags, diag)
+			}
+		}
+	}
+	sortDiagnostics(diags)
+	return diags, nil
+}
+
+// sortDiagnostics sorts diagnostics by their position.
+func sortDiagnostics(diags []Diagnostic) {
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i].Start, diags[j].Start
+		if a.Filename != b.Filename {
+			return a.Filename < b.Filename
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		if a.Column != b.Column {
+			return a.Column < b.Column
+		}
+		return diags[i].Title < diags[j].Title
+	})
+}
+
+func position(file, line, col string) token.Position {
+	pos := token.Position{Filename: file}
+	pos.Line, _ = strconv.Atoi(line)
+	pos.Column, _ = strconv.Atoi(col)
+	return pos
+}
+
+// Error logs the diagnostics as error annotations.
+func Error(diags []Diagnostic) {
+	for _, d := range diags {
+		log.ErrorfAt(d.annotation(), "%s", d.Message)
+	}
+}
+
+// Warn logs the diagnostics as warning annotations.
+func Warn(diags []Diagnostic) {
+	for _, d := range diags {
+		log.WarnfAt(d.annotation(), "%s", d.Message)
+	}
+}
+
+func (d Diagnostic) annotation() log.Annotation {
+	return log.Annotation{Title: d.Title, Start: d.Start, End: d.End}
+}
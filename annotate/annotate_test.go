@@ -0,0 +1,127 @@
+package annotate
+
+import (
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/posener/goaction/goactiontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		output string
+		want   []Diagnostic
+	}{
+		{
+			name: "go build",
+			output: `# github.com/foo/bar
+./main.go:10:3: undefined: x
+./main.go:12:2: cannot use y (variable of type int) as string value in argument to f:
+	more details
+`,
+			want: []Diagnostic{
+				{Start: token.Position{Filename: "./main.go", Line: 10, Column: 3}, Message: "undefined: x"},
+				{Start: token.Position{Filename: "./main.go", Line: 12, Column: 2}, Message: "cannot use y (variable of type int) as string value in argument to f:\nmore details"},
+			},
+		},
+		{
+			name: "go vet",
+			output: `# github.com/foo/bar
+vet: main.go:6:14: fmt.Printf format %d has arg "s" of wrong type string
+sub/a.go:8: self-assignment of x
+`,
+			want: []Diagnostic{
+				{Start: token.Position{Filename: "main.go", Line: 6, Column: 14}, Message: `fmt.Printf format %d has arg "s" of wrong type string`},
+				{Start: token.Position{Filename: "sub/a.go", Line: 8}, Message: "self-assignment of x"},
+			},
+		},
+		{
+			name: "go vet -json",
+			output: `# github.com/foo/bar
+{
+	"github.com/foo/bar": {
+		"printf": [
+			{
+				"posn": "/workspace/pkg/main.go:6:14",
+				"end": "/workspace/pkg/main.go:6:16",
+				"message": "fmt.Printf format %d has arg \"s\" of wrong type string"
+			}
+		],
+		"assign": [
+			{
+				"posn": "/workspace/pkg/main.go:3:2",
+				"end": "/workspace/pkg/main.go:3:2",
+				"message": "self-assignment of x"
+			}
+		],
+		"other": {
+			"error": "analysis failed"
+		}
+	}
+}
+`,
+			want: []Diagnostic{
+				{Title: "other", Message: "analysis failed"},
+				{Title: "assign", Start: token.Position{Filename: "/workspace/pkg/main.go", Line: 3, Column: 2}, Message: "self-assignment of x"},
+				{
+					Title:   "printf",
+					Start:   token.Position{Filename: "/workspace/pkg/main.go", Line: 6, Column: 14},
+					End:     token.Position{Filename: "/workspace/pkg/main.go", Line: 6, Column: 16},
+					Message: `fmt.Printf format %d has arg "s" of wrong type string`,
+				},
+			},
+		},
+		{
+			name:   "gofmt -l",
+			output: "main.go\nsub/a.go\n",
+			want: []Diagnostic{
+				{Title: "gofmt", Start: token.Position{Filename: "main.go"}, Message: "File is not formatted, run gofmt."},
+				{Title: "gofmt", Start: token.Position{Filename: "sub/a.go"}, Message: "File is not formatted, run gofmt."},
+			},
+		},
+		{
+			name:   "other lines",
+			output: "FAIL\nok  \tgithub.com/foo/bar\nexit status 1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(strings.NewReader(tt.output))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseInvalidJSON(t *testing.T) {
+	t.Parallel()
+
+	_, err := Parse(strings.NewReader("{\n\t\"pkg\": []\n}\n"))
+	assert.Error(t, err)
+	_, err = Parse(strings.NewReader("{\n"))
+	assert.Error(t, err)
+}
+
+func TestErrorWarn(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	diags := []Diagnostic{
+		{Title: "printf", Start: token.Position{Filename: "main.go", Line: 6, Column: 14}, End: token.Position{Line: 6, Column: 16}, Message: "bad format"},
+	}
+	Error(diags)
+	Warn(diags)
+
+	want := goactiontest.Annotation{Title: "printf", File: "main.go", Line: 6, Column: 14, EndLine: 6, EndColumn: 16, Message: "bad format"}
+	wantErr, wantWarn := want, want
+	wantErr.Level, wantWarn.Level = "error", "warning"
+	assert.Equal(t, []goactiontest.Annotation{wantErr, wantWarn}, env.Annotations())
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/posener/goaction/annotate"
+)
+
+const annotateUsage = `Usage: <go tool> 2>&1 | goaction annotate [flags]
+
+Annotate converts the output of Go tools, which is read from stdin, to Github action annotations.
+Supported outputs are of go build, go vet, go vet -json, gofmt -l and any tool that prints lines
+of the form file:line:col: message, such as staticcheck. It fails if error annotations were made.
+
+Flags:
+`
+
+// annotateCmd is the entrypoint of the annotate subcommand.
+func annotateCmd(args []string) error {
+	fs := flag.NewFlagSet("annotate", flag.ContinueOnError)
+	warn := fs.Bool("warn", false, "Annotate as warnings instead of errors, and don't fail.")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), annotateUsage)
+		fs.PrintDefaults()
+	}
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	return annotateReader(os.Stdin, *warn)
+}
+
+func annotateReader(r io.Reader, warn bool) error {
+	diags, err := annotate.Parse(r)
+	if err != nil {
+		return err
+	}
+	if warn {
+		annotate.Warn(diags)
+		return nil
+	}
+	annotate.Error(diags)
+	if len(diags) > 0 {
+		// The errors were annotated.
+		return errFailed
+	}
+	return nil
+}
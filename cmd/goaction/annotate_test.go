@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/posener/goaction/goactiontest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnotateReader(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	err := annotateReader(strings.NewReader("main.go:1:2: bad\n"), false)
+	assert.Equal(t, errFailed, err)
+	err = annotateReader(strings.NewReader("main.go:1:2: bad\n"), true)
+	assert.NoError(t, err)
+	err = annotateReader(strings.NewReader("ok\n"), false)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []goactiontest.Annotation{
+		{Level: "error", File: "main.go", Line: 1, Column: 2, Message: "bad"},
+		{Level: "warning", File: "main.go", Line: 1, Column: 2, Message: "bad"},
+	}, env.Annotations())
+}
@@ -32,11 +32,14 @@ var (
 	install = flag.String("install", "", "Comma separated list of requirements to 'apk add'.")
 	icon    = flag.String("icon", "", "Set branding icon. (See options at https://feathericons.com).")
 	color   = flag.String("color", "", "Set branding color. (white, yellow, blue, green, orange, red, purple or gray-dark).")
+	pre     = flag.Bool("pre", false, "Run the action also in a pre phase (See goaction.Phase).")
+	post    = flag.Bool("post", false, "Run the action also in a post phase (See goaction.Phase).")
 
 	//goaction:description Email for commit message.
 	//goaction:default posener@gmail.com
 	email = os.Getenv("email")
 	//goaction:description Github token for PR comments. Optional.
+	//goaction:secret
 	githubToken = os.Getenv("GITHUB_TOKEN")
 )
 
@@ -44,32 +47,45 @@ const (
 	action      = "action.yml"
 	dockerfile  = "Dockerfile"
 	autoComment = "# File generated by github.com/posener/goaction. DO NOT EDIT.\n\n"
+
+	// Entrypoints of the pre and post phases. goaction.Phase infers the phase from them.
+	preEntrypoint  = "/bin/" + goaction.PreExecutable
+	postEntrypoint = "/bin/" + goaction.PostExecutable
 )
 
-func main() {
-	flag.Parse()
+// errFailed is returned by a subcommand that already logged why it failed, such that the program
+// fails without logging it again.
+var errFailed = errors.New("failed")
 
-	// Load go code.
-	fset := token.NewFileSet()
-	pkgs, err := parser.ParseDir(fset, *path, nil, parser.ParseComments)
-	if err != nil {
-		log.Fatal(err)
-	}
+// Subcommands of the goaction command.
+var subcommands = map[string]func(args []string) error{
+	"run":      run,
+	"annotate": annotateCmd,
+	"report":   reportCmd,
+}
 
-	// Get main package.
-	var mainPkg *ast.Package
-	for name, pkg := range pkgs {
-		if name == "main" {
-			mainPkg = pkg
-			break
+func main() {
+	// Subcommands have their own flags, and are handled before the action flags are parsed.
+	if len(os.Args) > 1 {
+		if cmd, ok := subcommands[os.Args[1]]; ok {
+			err := cmd(os.Args[2:])
+			if err != nil && err != errFailed {
+				log.Fatal(err)
+			}
+			flushErr := log.Flush()
+			if flushErr != nil {
+				log.Fatal(flushErr)
+			}
+			if err == errFailed {
+				os.Exit(1)
+			}
+			return
 		}
 	}
-	if mainPkg == nil {
-		log.Fatalf("No main package in path %q", *path)
-	}
 
-	// Parse Go code to Github actions metadata.
-	m, err := metadata.New(mainPkg)
+	flag.Parse()
+
+	fset, m, err := loadMetadata(*path)
 	if err != nil {
 		// For parsing error, log the file location.
 		var pe metadata.ErrParse
@@ -88,6 +104,13 @@ func main() {
 	m.Branding.Icon = *icon
 	m.Branding.Color = *color
 
+	if *pre {
+		m.Runs.PreEntrypoint = preEntrypoint
+	}
+	if *post {
+		m.Runs.PostEntrypoint = postEntrypoint
+	}
+
 	// Applying changes.
 
 	// Create action file.
@@ -107,6 +130,7 @@ func main() {
 		Dir:     dir,
 		Image:   *image,
 		Install: strings.ReplaceAll(*install, ",", " "),
+		Links:   links(m.Runs),
 	}
 	err = script.Writer("template", func(w io.Writer) error {
 		w.Write([]byte(autoComment))
@@ -149,6 +173,32 @@ func main() {
 	}
 }
 
+// loadMetadata loads the main package in the given path and parses it to Github actions metadata.
+func loadMetadata(path string) (*token.FileSet, metadata.Metadata, error) {
+	// Load go code.
+	fset := token.NewFileSet()
+	pkgs, err := parser.ParseDir(fset, path, nil, parser.ParseComments)
+	if err != nil {
+		return fset, metadata.Metadata{}, err
+	}
+
+	// Get main package.
+	var mainPkg *ast.Package
+	for name, pkg := range pkgs {
+		if name == "main" {
+			mainPkg = pkg
+			break
+		}
+	}
+	if mainPkg == nil {
+		return fset, metadata.Metadata{}, fmt.Errorf("no main package in path %q", path)
+	}
+
+	// Parse Go code to Github actions metadata.
+	m, err := metadata.New(mainPkg)
+	return fset, m, err
+}
+
 func gitDiff() string {
 	var diff strings.Builder
 	for _, path := range []string{action, dockerfile} {
@@ -222,10 +272,23 @@ func pathRelDir(path string) (string, error) {
 	return path, nil
 }
 
+// links returns the entrypoints of the pre and post phases, which should be linked to the action
+// binary.
+func links(runs metadata.Runs) []string {
+	var links []string
+	for _, entrypoint := range []string{runs.PreEntrypoint, runs.PostEntrypoint} {
+		if entrypoint != "" {
+			links = append(links, entrypoint)
+		}
+	}
+	return links
+}
+
 type tmplData struct {
 	Dir     string
 	Image   string
 	Install string
+	Links   []string
 }
 
 var tmpl = template.Must(template.New("dockerfile").Parse(`
@@ -235,6 +298,9 @@ RUN apk add git {{ .Install }}
 COPY . /home/src
 WORKDIR /home/src
 RUN go build -o /bin/action {{ .Dir }}
+{{- range .Links }}
+RUN ln -s /bin/action {{ . }}
+{{- end }}
 
 ENTRYPOINT [ "/bin/action" ]
 `))
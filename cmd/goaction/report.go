@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/posener/goaction"
+	"github.com/posener/goaction/log"
+	"github.com/posener/goaction/testreport"
+)
+
+const reportUsage = `Usage: go test -json ./... | goaction report [flags]
+
+Report reads the output of go test -json from stdin and reports the test results in the Github
+action: it annotates the failing tests at the file locations of their errors, adds a results table
+to the job summary, and optionally sets the test totals as the outputs "passed", "failed" and
+"skipped". It fails if the tests failed.
+
+Flags:
+`
+
+type reportConfig struct {
+	annotate bool
+	summary  bool
+	outputs  bool
+	slowest  int
+}
+
+// reportCmd is the entrypoint of the report subcommand.
+func reportCmd(args []string) error {
+	var cfg reportConfig
+	fs := flag.NewFlagSet("report", flag.ContinueOnError)
+	fs.BoolVar(&cfg.annotate, "annotate", true, "Annotate failing tests.")
+	fs.BoolVar(&cfg.summary, "summary", true, "Add the test results to the job summary.")
+	fs.BoolVar(&cfg.outputs, "outputs", false, "Set the test totals as action outputs.")
+	fs.IntVar(&cfg.slowest, "slowest", 10, "Number of slowest tests to show in the job summary.")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), reportUsage)
+		fs.PrintDefaults()
+	}
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	return cfg.report(os.Stdin)
+}
+
+func (cfg reportConfig) report(r io.Reader) error {
+	// The test output is untrusted, and should not trigger workflow commands.
+	out := log.StopCommandsWriter(log.Writer())
+	report, err := testreport.Parse(r, out)
+	if err != nil {
+		out.Close()
+		return err
+	}
+	err = out.Close()
+	if err != nil {
+		return err
+	}
+
+	if cfg.annotate {
+		report.Annotate()
+	}
+	if cfg.summary {
+		err = report.Summary(goaction.NewSummary(), cfg.slowest).Write()
+		if err != nil {
+			return err
+		}
+	}
+	if cfg.outputs {
+		err = report.Output()
+		if err != nil {
+			return err
+		}
+	}
+	if report.Failed() {
+		// The failures were printed in the test output, and annotated unless it was disabled.
+		return errFailed
+	}
+	return nil
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/posener/goaction/goactiontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReportFailed(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	f, err := os.Open("../../testreport/testdata/test.json")
+	require.NoError(t, err)
+	defer f.Close()
+
+	err = reportConfig{annotate: true}.report(f)
+	// The failed tests are annotated, and the report fails without logging another error.
+	assert.Equal(t, errFailed, err)
+	assert.NotEmpty(t, env.Annotations())
+}
@@ -0,0 +1,448 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/posener/goaction/internal/command"
+	"github.com/posener/goaction/internal/envfile"
+	"github.com/posener/goaction/internal/metadata"
+)
+
+const runUsage = `Usage: goaction run [flags] [-input name=value...]
+
+Run simulates a Github action run of the main package in the given path, as the Github runner
+would have run it in a workflow. The action gets the inputs, the arguments and the environment
+variables that are defined by its action.yml file, and runs in CI mode. When the action finishes,
+the outputs, environment changes, path changes, annotations and job summary are printed.
+
+Flags:
+`
+
+// Matches expressions of inputs in the action.yml file, for example: "${{ inputs.name }}".
+var inputExpr = regexp.MustCompile(`\$\{\{\s*inputs\.([\w-]+)\s*\}\}`)
+
+// runConfig is the configuration of a local action run.
+type runConfig struct {
+	path      string
+	event     string
+	eventPath string
+	inputs    keyValues
+	env       keyValues
+	stdout    io.Writer
+	stderr    io.Writer
+}
+
+// keyValues is a flag that can be set multiple times with name=value pairs.
+type keyValues map[string]string
+
+func (kv keyValues) String() string {
+	var pairs []string
+	for k, v := range kv {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (kv keyValues) Set(s string) error {
+	parts := strings.SplitN(s, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return fmt.Errorf("expected name=value, got %q", s)
+	}
+	kv[parts[0]] = parts[1]
+	return nil
+}
+
+// run is the entrypoint of the run subcommand.
+func run(args []string) error {
+	cfg := runConfig{
+		inputs: keyValues{},
+		env:    keyValues{},
+		stdout: os.Stdout,
+		stderr: os.Stderr,
+	}
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.StringVar(&cfg.path, "path", ".", "Path to main Go main package.")
+	fs.StringVar(&cfg.event, "event", "push", "Name of the event that triggers the action.")
+	fs.StringVar(&cfg.eventPath, "event-path", "", "Path to a JSON file with the event payload. Defaults to an empty payload.")
+	fs.Var(cfg.inputs, "input", "Action input in the form name=value. Can be given multiple times.")
+	fs.Var(cfg.env, "env", "Additional environment variable in the form name=value. Can be given multiple times.")
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), runUsage)
+		fs.PrintDefaults()
+	}
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	return cfg.run()
+}
+
+func (cfg runConfig) run() error {
+	_, m, err := loadMetadata(cfg.path)
+	if err != nil {
+		return err
+	}
+
+	dir, err := ioutil.TempDir("", "goaction-run")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	files := map[string]string{
+		"GITHUB_ENV":          filepath.Join(dir, "env"),
+		"GITHUB_OUTPUT":       filepath.Join(dir, "output"),
+		"GITHUB_PATH":         filepath.Join(dir, "path"),
+		"GITHUB_STEP_SUMMARY": filepath.Join(dir, "summary"),
+		"GITHUB_STATE":        filepath.Join(dir, "state"),
+		"GITHUB_EVENT_PATH":   filepath.Join(dir, "event.json"),
+		"RUNNER_TEMP":         filepath.Join(dir, "tmp"),
+	}
+	for name, path := range files {
+		if name == "RUNNER_TEMP" {
+			err = os.Mkdir(path, 0755)
+		} else {
+			err = ioutil.WriteFile(path, nil, 0644)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	payload := []byte("{}")
+	if cfg.eventPath != "" {
+		payload, err = ioutil.ReadFile(cfg.eventPath)
+		if err != nil {
+			return fmt.Errorf("reading event payload: %s", err)
+		}
+	}
+	err = ioutil.WriteFile(files["GITHUB_EVENT_PATH"], payload, 0644)
+	if err != nil {
+		return err
+	}
+
+	inputs, err := inputValues(m, cfg.inputs)
+	if err != nil {
+		return err
+	}
+	args, env := actionArgsEnv(m, inputs)
+
+	// Build the action binary. The package is built from its directory, since the go command
+	// interprets a relative path without a "./" prefix as an import path.
+	bin := filepath.Join(dir, "action")
+	build := exec.Command("go", "build", "-o", bin, ".")
+	build.Dir = cfg.path
+	build.Stdout = cfg.stderr
+	build.Stderr = cfg.stderr
+	err = build.Run()
+	if err != nil {
+		return fmt.Errorf("building action: %s", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+	environ := os.Environ()
+	for name, value := range defaultEnv(wd, cfg.event) {
+		environ = append(environ, name+"="+value)
+	}
+	for name, path := range files {
+		environ = append(environ, name+"="+path)
+	}
+	environ = append(environ, env...)
+	for name, value := range cfg.env {
+		environ = append(environ, name+"="+value)
+	}
+
+	// Run the action binary, and collect workflow commands from its output.
+	var cmds []command.Command
+	pr, pw := io.Pipe()
+	done := make(chan error)
+	go func() {
+		var err error
+		cmds, err = copyCommands(cfg.stdout, pr)
+		done <- err
+	}()
+	action := exec.Command(bin, args...)
+	action.Env = environ
+	action.Stdout = pw
+	action.Stderr = cfg.stderr
+	runErr := action.Run()
+	pw.Close()
+	err = <-done
+	if err != nil {
+		return err
+	}
+
+	err = cfg.report(files, cmds)
+	if err != nil {
+		return err
+	}
+	if runErr != nil {
+		return fmt.Errorf("action failed: %s", runErr)
+	}
+	return nil
+}
+
+// inputValues returns the values of all the action inputs, from the given inputs or from their
+// default values.
+func inputValues(m metadata.Metadata, given map[string]string) (map[string]string, error) {
+	values := map[string]string{}
+	for _, item := range m.Inputs {
+		name := item.Key.(string)
+		in := item.Value.(metadata.Input)
+		value, ok := given[name]
+		if !ok && in.Default != nil {
+			value = fmt.Sprint(in.Default)
+		}
+		if in.Required && value == "" {
+			return nil, fmt.Errorf("input required and not supplied: %s", name)
+		}
+		values[name] = value
+	}
+	for name := range given {
+		if _, ok := values[name]; !ok {
+			return nil, fmt.Errorf("unknown input: %s", name)
+		}
+	}
+	return values, nil
+}
+
+// actionArgsEnv returns the arguments and the environment variables that the Github runner passes
+// to the action, as defined in the action.yml file.
+func actionArgsEnv(m metadata.Metadata, inputs map[string]string) (args []string, env []string) {
+	for _, arg := range m.Runs.Args {
+		args = append(args, expandInputs(arg, inputs))
+	}
+	// Github passes all inputs in INPUT_<NAME> environment variables.
+	for name, value := range inputs {
+		env = append(env, "INPUT_"+strings.ToUpper(strings.ReplaceAll(name, " ", "_"))+"="+value)
+	}
+	for _, item := range m.Runs.Env {
+		env = append(env, item.Key.(string)+"="+expandInputs(fmt.Sprint(item.Value), inputs))
+	}
+	return args, env
+}
+
+// expandInputs replaces input expressions in a value from the action.yml file with the input
+// values.
+func expandInputs(s string, inputs map[string]string) string {
+	// Values in the metadata are quoted YAML strings.
+	if uq, err := strconv.Unquote(s); err == nil {
+		s = uq
+	}
+	return inputExpr.ReplaceAllStringFunc(s, func(expr string) string {
+		return inputs[inputExpr.FindStringSubmatch(expr)[1]]
+	})
+}
+
+// defaultEnv returns the default environment variables of a Github action run.
+func defaultEnv(wd string, event string) map[string]string {
+	env := map[string]string{
+		"CI":                 "true",
+		"GITHUB_ACTIONS":     "true",
+		"GITHUB_WORKSPACE":   wd,
+		"GITHUB_EVENT_NAME":  event,
+		"GITHUB_WORKFLOW":    "goaction run",
+		"GITHUB_JOB":         "run",
+		"GITHUB_ACTION":      "run",
+		"GITHUB_RUN_ID":      "1",
+		"GITHUB_RUN_NUMBER":  "1",
+		"GITHUB_RUN_ATTEMPT": "1",
+		"GITHUB_ACTOR":       "goaction",
+		"GITHUB_REPOSITORY":  "goaction/run",
+		"GITHUB_SERVER_URL":  "https://github.com",
+		"GITHUB_API_URL":     "https://api.github.com",
+		"GITHUB_GRAPHQL_URL": "https://api.github.com/graphql",
+		"RUNNER_OS":          runnerOS(),
+		"RUNNER_ARCH":        runnerArch(),
+	}
+	if sha, err := git(wd, "rev-parse", "HEAD"); err == nil {
+		env["GITHUB_SHA"] = sha
+	}
+	if ref, err := git(wd, "symbolic-ref", "HEAD"); err == nil {
+		env["GITHUB_REF"] = ref
+		env["GITHUB_REF_NAME"] = strings.TrimPrefix(ref, "refs/heads/")
+		env["GITHUB_REF_TYPE"] = "branch"
+	}
+	return env
+}
+
+func git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+func runnerOS() string {
+	switch runtime.GOOS {
+	case "windows":
+		return "Windows"
+	case "darwin":
+		return "macOS"
+	default:
+		return "Linux"
+	}
+}
+
+func runnerArch() string {
+	switch runtime.GOARCH {
+	case "386":
+		return "X86"
+	case "arm":
+		return "ARM"
+	case "arm64":
+		return "ARM64"
+	default:
+		return "X64"
+	}
+}
+
+// copyCommands copies the action output and collects the workflow commands in it. Like the Github
+// runner, masked terms are replaced with "***" from the line that masks them onward.
+func copyCommands(w io.Writer, r io.Reader) ([]command.Command, error) {
+	var (
+		cmds   []command.Command
+		stream command.Stream
+		m      masker
+	)
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if len(line) > 0 {
+			if cmd, ok := stream.Parse(line); ok {
+				cmds = append(cmds, cmd)
+				if cmd.Name == "add-mask" {
+					m.add(cmd.Message)
+				}
+			}
+			if _, werr := io.WriteString(w, m.redact(line)); werr != nil {
+				return nil, werr
+			}
+		}
+		if err == io.EOF {
+			return cmds, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// report prints the results of the action run. The masked terms are redacted from the report.
+func (cfg runConfig) report(files map[string]string, cmds []command.Command) error {
+	var (
+		w      strings.Builder
+		m      masker
+		masked int
+	)
+	for _, cmd := range cmds {
+		if cmd.Name == "add-mask" {
+			m.add(cmd.Message)
+			masked++
+		}
+	}
+	fmt.Fprintf(&w, "\n=== goaction run results ===\n")
+
+	for _, section := range []struct{ title, file string }{
+		{title: "Outputs", file: "GITHUB_OUTPUT"},
+		{title: "Environment", file: "GITHUB_ENV"},
+		{title: "State", file: "GITHUB_STATE"},
+	} {
+		vars, err := envfile.ParseFile(files[section.file])
+		if err != nil {
+			return fmt.Errorf("parsing %s: %s", section.file, err)
+		}
+		// Support the deprecated commands.
+		for _, cmd := range cmds {
+			if (section.file == "GITHUB_OUTPUT" && cmd.Name == "set-output") ||
+				(section.file == "GITHUB_STATE" && cmd.Name == "save-state") {
+				vars = append(vars, envfile.Var{Name: cmd.Property("name"), Value: cmd.Message})
+			}
+		}
+		fmt.Fprintf(&w, "\n%s:\n", section.title)
+		for _, v := range vars {
+			fmt.Fprintf(&w, "  %s=%s\n", v.Name, indent(v.Value))
+		}
+	}
+
+	paths, err := ioutil.ReadFile(files["GITHUB_PATH"])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&w, "\nPath:\n")
+	for _, p := range strings.Split(strings.TrimSpace(string(paths)), "\n") {
+		if p != "" {
+			fmt.Fprintf(&w, "  %s\n", p)
+		}
+	}
+
+	fmt.Fprintf(&w, "\nAnnotations:\n")
+	for _, cmd := range cmds {
+		switch cmd.Name {
+		case "error", "warning", "notice":
+		default:
+			continue
+		}
+		var props []string
+		for _, p := range cmd.Properties {
+			props = append(props, p.Key+"="+p.Value)
+		}
+		loc := ""
+		if len(props) > 0 {
+			loc = " (" + strings.Join(props, ",") + ")"
+		}
+		fmt.Fprintf(&w, "  %s%s: %s\n", cmd.Name, loc, indent(cmd.Message))
+	}
+
+	// The masked values are secrets, so only their number is printed.
+	fmt.Fprintf(&w, "\nMasked:\n  %d values\n", masked)
+
+	summary, err := ioutil.ReadFile(files["GITHUB_STEP_SUMMARY"])
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(&w, "\nSummary:\n%s\n", summary)
+	_, err = io.WriteString(cfg.stdout, m.redact(w.String()))
+	return err
+}
+
+// masker redacts masked terms from text.
+type masker struct {
+	terms []string
+}
+
+func (m *masker) add(term string) {
+	if term == "" {
+		return
+	}
+	m.terms = append(m.terms, term)
+	// Replace longer terms first, such that a term that contains another term is fully redacted.
+	sort.SliceStable(m.terms, func(i, j int) bool { return len(m.terms[i]) > len(m.terms[j]) })
+}
+
+func (m *masker) redact(s string) string {
+	for _, term := range m.terms {
+		s = strings.ReplaceAll(s, term, "***")
+	}
+	return s
+}
+
+// indent indents all the lines after the first line of a multiline value.
+func indent(s string) string {
+	return strings.ReplaceAll(s, "\n", "\n    ")
+}
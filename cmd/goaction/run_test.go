@@ -0,0 +1,119 @@
+package main
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/goccy/go-yaml"
+	"github.com/posener/goaction/internal/metadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyValues(t *testing.T) {
+	t.Parallel()
+
+	kv := keyValues{}
+	require.NoError(t, kv.Set("a=1"))
+	require.NoError(t, kv.Set("b=x=y"))
+	require.NoError(t, kv.Set("c="))
+	assert.Equal(t, keyValues{"a": "1", "b": "x=y", "c": ""}, kv)
+	assert.Equal(t, "a=1,b=x=y,c=", kv.String())
+
+	assert.Error(t, kv.Set("a"))
+	assert.Error(t, kv.Set("=1"))
+}
+
+func TestInputValues(t *testing.T) {
+	t.Parallel()
+
+	m := metadata.Metadata{
+		Inputs: yaml.MapSlice{
+			{Key: "required", Value: metadata.Input{Required: true}},
+			{Key: "default", Value: metadata.Input{Default: 42}},
+			{Key: "optional", Value: metadata.Input{}},
+		},
+	}
+
+	got, err := inputValues(m, map[string]string{"required": "foo"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"required": "foo", "default": "42", "optional": ""}, got)
+
+	got, err = inputValues(m, map[string]string{"required": "foo", "default": "1"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"required": "foo", "default": "1", "optional": ""}, got)
+
+	_, err = inputValues(m, map[string]string{})
+	assert.Error(t, err)
+
+	_, err = inputValues(m, map[string]string{"required": "foo", "unknown": "bar"})
+	assert.Error(t, err)
+}
+
+func TestActionArgsEnv(t *testing.T) {
+	t.Parallel()
+
+	m := metadata.Metadata{
+		Runs: metadata.Runs{
+			Env: yaml.MapSlice{
+				{Key: "foo", Value: `"${{ inputs.foo }}"`},
+				{Key: "CONST", Value: `"const"`},
+			},
+			Args: []string{`"-bar=${{ inputs.bar }}"`, `"-baz=${{inputs.baz}}"`},
+		},
+	}
+	inputs := map[string]string{"foo": "1", "bar": "2", "baz": "a b"}
+
+	args, env := actionArgsEnv(m, inputs)
+	assert.Equal(t, []string{"-bar=2", "-baz=a b"}, args)
+	assert.ElementsMatch(t, []string{"INPUT_FOO=1", "INPUT_BAR=2", "INPUT_BAZ=a b", "foo=1", "CONST=const"}, env)
+}
+
+func TestRun(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the test action")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cfg := runConfig{
+		path:   "./testdata/action",
+		event:  "push",
+		inputs: keyValues{"who": "bob"},
+		env:    keyValues{},
+		stdout: &stdout,
+		stderr: &stderr,
+	}
+	err := cfg.run()
+	require.NoError(t, err, stderr.String())
+
+	out := stdout.String()
+	assert.Contains(t, out, "::warning::greeting bob\n")
+	assert.Contains(t, out, "Outputs:\n  message=hello bob\n")
+	assert.Contains(t, out, "Environment:\n  GREETED=bob\n")
+	assert.Contains(t, out, "Annotations:\n  warning: greeting bob\n")
+	assert.Contains(t, out, "Summary:\n## Greeted bob\n")
+}
+
+func TestRunRelativePathMasked(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the test action")
+	}
+
+	var stdout, stderr bytes.Buffer
+	cfg := runConfig{
+		path:   "testdata/action",
+		event:  "push",
+		inputs: keyValues{"who": "bob", "token": "s3cr3t"},
+		env:    keyValues{},
+		stdout: &stdout,
+		stderr: &stderr,
+	}
+	err := cfg.run()
+	require.NoError(t, err, stderr.String())
+
+	out := stdout.String()
+	assert.NotContains(t, out, "s3cr3t")
+	assert.Contains(t, out, "::add-mask::***\n")
+	assert.Contains(t, out, "token ***\n")
+	assert.Contains(t, out, "Masked:\n  2 values\n")
+}
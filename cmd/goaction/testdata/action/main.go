@@ -0,0 +1,37 @@
+// Action for testing the run subcommand.
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/posener/goaction"
+	"github.com/posener/goaction/log"
+)
+
+var (
+	//goaction:required
+	who = flag.String("who", "", "Who to greet.")
+	//goaction:default hello
+	greeting = os.Getenv("greeting")
+	//goaction:secret
+	token = os.Getenv("token")
+)
+
+func main() {
+	flag.Parse()
+	log.Warnf("greeting %s", *who)
+	log.Printf("token %s", token)
+	err := goaction.Output("message", greeting+" "+*who, "The greeting message.")
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = goaction.Setenv("GREETED", *who)
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = goaction.NewSummary().Heading(2, "Greeted "+*who).Write()
+	if err != nil {
+		log.Fatal(err)
+	}
+}
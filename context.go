@@ -0,0 +1,307 @@
+package goaction
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/posener/goaction/internal/command"
+	"github.com/posener/goaction/internal/envfile"
+)
+
+// Context holds the Github action environment. The package level variables and functions use a
+// default context that is loaded from the process environment. A Context can be created
+// explicitly, for example in tests, in order to work with a different environment.
+//
+// The fields are documented in the package level variables with the same names.
+type Context struct {
+	CI            bool
+	Home          string
+	Workflow      string
+	RunID         string
+	RunNum        string
+	ActionID      string
+	Actor         string
+	Repository    string
+	Event         EventType
+	Workspace     string
+	SHA           string
+	Ref           string
+	ForkedHeadRef string
+	ForkedBaseRef string
+
+	ServerURL       string
+	APIURL          string
+	GraphQLURL      string
+	RefName         string
+	RefType         RefKind
+	RunAttempt      int
+	Job             string
+	TriggeringActor string
+	WorkflowRef     string
+	RepositoryID    int64
+	ActionPath      string
+	RunnerOS        string
+	RunnerArch      string
+	RunnerTemp      string
+	RunnerToolCache string
+	RunnerDebug     bool
+
+	eventPath   string
+	envPath     string
+	outputPath  string
+	pathPath    string
+	summaryPath string
+	statePath   string
+
+	getenv func(string) string
+	setenv func(string, string) error
+	// Output of workflow commands, for runners that don't support environment files.
+	stdout io.Writer
+}
+
+// FromEnv returns a context that is loaded from the process environment variables.
+func FromEnv() *Context {
+	return newContext(os.Getenv, os.Setenv)
+}
+
+// FromMap returns a context that is loaded from the given environment variables. Variables that
+// are set for the currently running action, by Export and AddPathNow, are set in the map.
+func FromMap(env map[string]string) *Context {
+	if env == nil {
+		env = map[string]string{}
+	}
+	return newContext(
+		func(name string) string { return env[name] },
+		func(name, value string) error {
+			env[name] = value
+			return nil
+		},
+	)
+}
+
+func newContext(getenv func(string) string, setenv func(string, string) error) *Context {
+	return &Context{
+		CI:            getenv("CI") == "true",
+		Home:          getenv("HOME"),
+		Workflow:      getenv("GITHUB_WORKFLOW"),
+		RunID:         getenv("GITHUB_RUN_ID"),
+		RunNum:        getenv("GITHUB_RUN_NUMBER"),
+		ActionID:      getenv("GITHUB_ACTION"),
+		Actor:         getenv("GITHUB_ACTOR"),
+		Repository:    getenv("GITHUB_REPOSITORY"),
+		Event:         EventType(getenv("GITHUB_EVENT_NAME")),
+		Workspace:     getenv("GITHUB_WORKSPACE"),
+		SHA:           getenv("GITHUB_SHA"),
+		Ref:           getenv("GITHUB_REF"),
+		ForkedHeadRef: getenv("GITHUB_HEAD_REF"),
+		ForkedBaseRef: getenv("GITHUB_BASE_REF"),
+
+		ServerURL:       getenv("GITHUB_SERVER_URL"),
+		APIURL:          getenv("GITHUB_API_URL"),
+		GraphQLURL:      getenv("GITHUB_GRAPHQL_URL"),
+		RefName:         getenv("GITHUB_REF_NAME"),
+		RefType:         refType(getenv("GITHUB_REF_TYPE")),
+		RunAttempt:      int(parseInt(getenv("GITHUB_RUN_ATTEMPT"))),
+		Job:             getenv("GITHUB_JOB"),
+		TriggeringActor: getenv("GITHUB_TRIGGERING_ACTOR"),
+		WorkflowRef:     getenv("GITHUB_WORKFLOW_REF"),
+		RepositoryID:    parseInt(getenv("GITHUB_REPOSITORY_ID")),
+		ActionPath:      getenv("GITHUB_ACTION_PATH"),
+		RunnerOS:        getenv("RUNNER_OS"),
+		RunnerArch:      getenv("RUNNER_ARCH"),
+		RunnerTemp:      getenv("RUNNER_TEMP"),
+		RunnerToolCache: getenv("RUNNER_TOOL_CACHE"),
+		RunnerDebug:     getenv("RUNNER_DEBUG") == "1",
+
+		eventPath:   getenv("GITHUB_EVENT_PATH"),
+		envPath:     getenv("GITHUB_ENV"),
+		outputPath:  getenv("GITHUB_OUTPUT"),
+		pathPath:    getenv("GITHUB_PATH"),
+		summaryPath: getenv("GITHUB_STEP_SUMMARY"),
+		statePath:   getenv("GITHUB_STATE"),
+
+		getenv: getenv,
+		setenv: setenv,
+		stdout: os.Stdout,
+	}
+}
+
+// refType returns the kind of the ref that triggered the workflow run. A missing value is
+// RefUnknown.
+func refType(s string) RefKind {
+	if s == "" {
+		return RefUnknown
+	}
+	return RefKind(s)
+}
+
+// parseInt parses a numeric environment variable. A missing or invalid value is zero.
+func parseInt(s string) int64 {
+	n, _ := strconv.ParseInt(s, 10, 64)
+	return n
+}
+
+// SetCommandOutput sets the output destination of the workflow commands that the context prints.
+// Commands are printed only on runners that don't support environment files. By default, they are
+// printed to stdout.
+func (c *Context) SetCommandOutput(w io.Writer) {
+	c.stdout = w
+}
+
+// Getenv returns the value of an environment variable in the context.
+func (c *Context) Getenv(name string) string {
+	return c.getenv(name)
+}
+
+// Setenv sets an environment variable that will only be visible for all following Github actions in
+// the current workflow, but not in the current action. The value may contain multiple lines.
+// See  https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions#environment-files.
+func (c *Context) Setenv(name string, value string) error {
+	if !envName.MatchString(name) {
+		return fmt.Errorf("invalid environment variable name %q", name)
+	}
+	if !c.CI {
+		return nil
+	}
+	// Store in the given environment variable name such that programs that expect this environment
+	// variable (not through goaction) can get it.
+	err := envfile.Append(c.envPath, name, value)
+	if err != nil {
+		return fmt.Errorf("failed writing to env file: %s", err)
+	}
+	return nil
+}
+
+// Export sets an environment variable that will also be visible for all following Github actions in
+// the current workflow.
+func (c *Context) Export(name string, value string) error {
+	if !envName.MatchString(name) {
+		return fmt.Errorf("invalid environment variable name %q", name)
+	}
+	err := c.setenv(name, value)
+	if err != nil {
+		return err
+	}
+	return c.Setenv(name, value)
+}
+
+// Output sets Github action output. The value may contain multiple lines.
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#setting-an-output-parameter.
+func (c *Context) Output(name string, value string, desc string) error {
+	if !outputName.MatchString(name) {
+		return fmt.Errorf("invalid output name %q", name)
+	}
+	if !c.CI {
+		return nil
+	}
+	if c.outputPath == "" {
+		// Older runners don't support the output file, fallback to the deprecated command.
+		fmt.Fprintln(c.stdout, command.New("set-output", value, command.Property{Key: "name", Value: name}))
+		return nil
+	}
+	return envfile.Append(c.outputPath, name, value)
+}
+
+// OutputJSON sets Github action output to the JSON encoding of the given value. It can be decoded in
+// the workflow using the `fromJSON` function.
+func (c *Context) OutputJSON(name string, value interface{}, desc string) error {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encoding output %s: %s", name, err)
+	}
+	return c.Output(name, string(b), desc)
+}
+
+// AddPath prepends a directory to the system PATH variable for all subsequent actions in the
+// current job. The currently running action cannot access the new path variable.
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#adding-a-system-path.
+func (c *Context) AddPath(path string) error {
+	if !c.CI {
+		return nil
+	}
+	err := envfile.AppendLine(c.pathPath, path)
+	if err != nil {
+		return fmt.Errorf("failed writing to path file: %s", err)
+	}
+	return nil
+}
+
+// AddPathNow prepends a directory to the system PATH variable for all subsequent actions in the
+// current job, and also to the PATH variable of the currently running action.
+func (c *Context) AddPathNow(path string) error {
+	err := c.setenv("PATH", path+string(os.PathListSeparator)+c.getenv("PATH"))
+	if err != nil {
+		return err
+	}
+	return c.AddPath(path)
+}
+
+// Owner returns the name of the owner of the Github repository.
+func (c *Context) Owner() string {
+	repoParts := strings.Split(c.Repository, "/")
+	if len(repoParts) < 2 {
+		return ""
+	}
+	return repoParts[0]
+}
+
+// Project returns the name of the project of the Github repository.
+func (c *Context) Project() string {
+	repoParts := strings.Split(c.Repository, "/")
+	if len(repoParts) < 2 {
+		return ""
+	}
+	return repoParts[1]
+}
+
+// Branch returns the branch name when the workflow was triggered by a branch, or an empty string
+// otherwise. Slashes in the branch name are kept.
+func (c *Context) Branch() string {
+	r := ParseRef(c.Ref)
+	if r.Kind != RefBranch {
+		return ""
+	}
+	return r.Name
+}
+
+// Tag returns the tag name when the workflow was triggered by a tag, or an empty string otherwise.
+func (c *Context) Tag() string {
+	r := ParseRef(c.Ref)
+	if r.Kind != RefTag {
+		return ""
+	}
+	return r.Name
+}
+
+// IsForked return true if the action is running on a forked repository.
+func (c *Context) IsForked() bool {
+	return c.ForkedBaseRef != ""
+}
+
+// RepositoryURL returns the URL of the Github repository.
+func (c *Context) RepositoryURL() string {
+	return c.serverURL() + "/" + c.Repository
+}
+
+// RunURL returns the URL of the current workflow run.
+func (c *Context) RunURL() string {
+	return c.RepositoryURL() + "/actions/runs/" + c.RunID
+}
+
+// CommitURL returns the URL of the commit that triggered the workflow.
+func (c *Context) CommitURL() string {
+	return c.RepositoryURL() + "/commit/" + c.SHA
+}
+
+// serverURL returns the Github server URL, which defaults to https://github.com for runners that
+// don't set it.
+func (c *Context) serverURL() string {
+	if c.ServerURL == "" {
+		return "https://github.com"
+	}
+	return strings.TrimSuffix(c.ServerURL, "/")
+}
@@ -0,0 +1,115 @@
+package goaction
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/posener/goaction/internal/envfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromMap(t *testing.T) {
+	dir, err := ioutil.TempDir("", "context")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	eventPath := filepath.Join(dir, "event.json")
+	require.NoError(t, ioutil.WriteFile(eventPath, []byte(`{"ref": "refs/heads/main"}`), 0644))
+
+	c := FromMap(map[string]string{
+		"CI":                   "true",
+		"GITHUB_REPOSITORY":    "posener/goaction",
+		"GITHUB_EVENT_NAME":    "push",
+		"GITHUB_EVENT_PATH":    eventPath,
+		"GITHUB_REF":           "refs/heads/main",
+		"GITHUB_REF_TYPE":      "branch",
+		"GITHUB_RUN_ATTEMPT":   "2",
+		"GITHUB_REPOSITORY_ID": "123456789",
+		"GITHUB_OUTPUT":        filepath.Join(dir, "output"),
+		"STATE_foo":            "bar",
+	})
+
+	assert.True(t, c.CI)
+	assert.Equal(t, "posener", c.Owner())
+	assert.Equal(t, "goaction", c.Project())
+	assert.Equal(t, "main", c.Branch())
+	assert.Equal(t, -1, c.PrNum())
+	assert.Equal(t, "bar", c.State("foo"))
+	assert.Equal(t, RefBranch, c.RefType)
+	assert.Equal(t, 2, c.RunAttempt)
+	assert.Equal(t, int64(123456789), c.RepositoryID)
+
+	push, err := c.GetPush()
+	require.NoError(t, err)
+	assert.Equal(t, "refs/heads/main", push.GetRef())
+	_, err = c.GetPullRequest()
+	assert.Error(t, err)
+
+	require.NoError(t, c.Output("out", "value", ""))
+	assertEnvFile(t, readFile(t, filepath.Join(dir, "output")), envfile.Var{Name: "out", Value: "value"})
+}
+
+func TestFromMapEmpty(t *testing.T) {
+	t.Parallel()
+
+	c := FromMap(nil)
+	assert.Equal(t, RefUnknown, c.RefType)
+	assert.Equal(t, 0, c.RunAttempt)
+	assert.Equal(t, int64(0), c.RepositoryID)
+}
+
+func TestURLs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		server  string
+		wantRun string
+	}{
+		{server: "", wantRun: "https://github.com/posener/goaction/actions/runs/42"},
+		{server: "https://ghes.example.com/", wantRun: "https://ghes.example.com/posener/goaction/actions/runs/42"},
+	}
+	for _, tt := range tests {
+		c := FromMap(map[string]string{
+			"GITHUB_SERVER_URL": tt.server,
+			"GITHUB_REPOSITORY": "posener/goaction",
+			"GITHUB_RUN_ID":     "42",
+			"GITHUB_SHA":        "abcd",
+		})
+		assert.Equal(t, tt.wantRun, c.RunURL())
+	}
+
+	c := FromMap(map[string]string{
+		"GITHUB_REPOSITORY": "posener/goaction",
+		"GITHUB_SHA":        "abcd",
+		"RUNNER_DEBUG":      "1",
+	})
+	assert.Equal(t, "https://github.com/posener/goaction/commit/abcd", c.CommitURL())
+	assert.True(t, c.RunnerDebug)
+}
+
+func TestContextProcessEnv(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{"PATH": "/bin"}
+	c := FromMap(env)
+
+	// Variables of the currently running action are set in the context and not in the process.
+	require.NoError(t, c.Export("GOACTION_TEST_EXPORT", "value"))
+	require.NoError(t, c.AddPathNow("/foo/bin"))
+	assert.Equal(t, "value", c.Getenv("GOACTION_TEST_EXPORT"))
+	assert.Equal(t, "/foo/bin"+string(os.PathListSeparator)+"/bin", env["PATH"])
+	_, ok := os.LookupEnv("GOACTION_TEST_EXPORT")
+	assert.False(t, ok)
+
+	// Without environment files, commands are printed to the command output.
+	var b bytes.Buffer
+	c.CI = true
+	c.SetCommandOutput(&b)
+	require.NoError(t, c.Output("out", "value", ""))
+	require.NoError(t, c.SaveState("state", "value"))
+	assert.Equal(t, "::set-output name=out::value\n::save-state name=state::value\n", b.String())
+}
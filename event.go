@@ -26,6 +26,7 @@ const (
 	EventIssueComment             EventType = "issue_comment"
 	EventIssues                   EventType = "issues"
 	EventLabel                    EventType = "label"
+	EventMergeGroup               EventType = "merge_group"
 	EventMilestone                EventType = "milestone"
 	EventPageBuild                EventType = "page_build"
 	EventProject                  EventType = "project"
@@ -34,6 +35,7 @@ const (
 	EventPullRequest              EventType = "pull_request"
 	EventPullRequestReview        EventType = "pull_request_review"
 	EventPullRequestReviewComment EventType = "pull_request_review_comment"
+	EventPullRequestTarget        EventType = "pull_request_target"
 	EventPush                     EventType = "push"
 	EventRegistryPackage          EventType = "registry_package"
 	EventRelease                  EventType = "release"
@@ -45,236 +47,366 @@ const (
 
 // GetCheckRun returns information about a current check run.
 func GetCheckRun() (*github.CheckRunEvent, error) {
-	if Event != EventCheckRun {
+	return current().GetCheckRun()
+}
+
+// GetCheckRun returns information about a current check run.
+func (c *Context) GetCheckRun() (*github.CheckRunEvent, error) {
+	if c.Event != EventCheckRun {
 		return nil, fmt.Errorf("not 'check_run' event")
 	}
 	var i github.CheckRunEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetCheckSuite returns information about a current check suite.
 func GetCheckSuite() (*github.CheckSuiteEvent, error) {
-	if Event != EventCheckSuite {
+	return current().GetCheckSuite()
+}
+
+// GetCheckSuite returns information about a current check suite.
+func (c *Context) GetCheckSuite() (*github.CheckSuiteEvent, error) {
+	if c.Event != EventCheckSuite {
 		return nil, fmt.Errorf("not 'check_suite' event")
 	}
 	var i github.CheckSuiteEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetCreate returns information about a current create.
 func GetCreate() (*github.CreateEvent, error) {
-	if Event != EventCreate {
+	return current().GetCreate()
+}
+
+// GetCreate returns information about a current create.
+func (c *Context) GetCreate() (*github.CreateEvent, error) {
+	if c.Event != EventCreate {
 		return nil, fmt.Errorf("not 'create' event")
 	}
 	var i github.CreateEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetDelete returns information about a current delete.
 func GetDelete() (*github.DeleteEvent, error) {
-	if Event != EventDelete {
+	return current().GetDelete()
+}
+
+// GetDelete returns information about a current delete.
+func (c *Context) GetDelete() (*github.DeleteEvent, error) {
+	if c.Event != EventDelete {
 		return nil, fmt.Errorf("not 'delete' event")
 	}
 	var i github.DeleteEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetDeployment returns information about a current deployment.
 func GetDeployment() (*github.DeploymentEvent, error) {
-	if Event != EventDeployment {
+	return current().GetDeployment()
+}
+
+// GetDeployment returns information about a current deployment.
+func (c *Context) GetDeployment() (*github.DeploymentEvent, error) {
+	if c.Event != EventDeployment {
 		return nil, fmt.Errorf("not 'deployment' event")
 	}
 	var i github.DeploymentEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetFork returns information about a current fork.
 func GetFork() (*github.ForkEvent, error) {
-	if Event != EventFork {
+	return current().GetFork()
+}
+
+// GetFork returns information about a current fork.
+func (c *Context) GetFork() (*github.ForkEvent, error) {
+	if c.Event != EventFork {
 		return nil, fmt.Errorf("not 'fork' event")
 	}
 	var i github.ForkEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetGollum returns information about a current gollum.
 func GetGollum() (*github.GollumEvent, error) {
-	if Event != EventGollum {
+	return current().GetGollum()
+}
+
+// GetGollum returns information about a current gollum.
+func (c *Context) GetGollum() (*github.GollumEvent, error) {
+	if c.Event != EventGollum {
 		return nil, fmt.Errorf("not 'gollum' event")
 	}
 	var i github.GollumEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetIssueComment returns information about a current issue comment.
 func GetIssueComment() (*github.IssueCommentEvent, error) {
-	if Event != EventIssueComment {
+	return current().GetIssueComment()
+}
+
+// GetIssueComment returns information about a current issue comment.
+func (c *Context) GetIssueComment() (*github.IssueCommentEvent, error) {
+	if c.Event != EventIssueComment {
 		return nil, fmt.Errorf("not 'issue_comment' event")
 	}
 	var i github.IssueCommentEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetIssues returns information about a current issues.
 func GetIssues() (*github.IssuesEvent, error) {
-	if Event != EventIssues {
+	return current().GetIssues()
+}
+
+// GetIssues returns information about a current issues.
+func (c *Context) GetIssues() (*github.IssuesEvent, error) {
+	if c.Event != EventIssues {
 		return nil, fmt.Errorf("not 'issues' event")
 	}
 	var i github.IssuesEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetLabel returns information about a current label.
 func GetLabel() (*github.LabelEvent, error) {
-	if Event != EventLabel {
+	return current().GetLabel()
+}
+
+// GetLabel returns information about a current label.
+func (c *Context) GetLabel() (*github.LabelEvent, error) {
+	if c.Event != EventLabel {
 		return nil, fmt.Errorf("not 'label' event")
 	}
 	var i github.LabelEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetMilestone returns information about a current milestone.
 func GetMilestone() (*github.MilestoneEvent, error) {
-	if Event != EventMilestone {
+	return current().GetMilestone()
+}
+
+// GetMilestone returns information about a current milestone.
+func (c *Context) GetMilestone() (*github.MilestoneEvent, error) {
+	if c.Event != EventMilestone {
 		return nil, fmt.Errorf("not 'milestone' event")
 	}
 	var i github.MilestoneEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetPageBuild returns information about a current page build.
 func GetPageBuild() (*github.PageBuildEvent, error) {
-	if Event != EventPageBuild {
+	return current().GetPageBuild()
+}
+
+// GetPageBuild returns information about a current page build.
+func (c *Context) GetPageBuild() (*github.PageBuildEvent, error) {
+	if c.Event != EventPageBuild {
 		return nil, fmt.Errorf("not 'page_build' event")
 	}
 	var i github.PageBuildEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetProject returns information about a current project.
 func GetProject() (*github.ProjectEvent, error) {
-	if Event != EventProject {
+	return current().GetProject()
+}
+
+// GetProject returns information about a current project.
+func (c *Context) GetProject() (*github.ProjectEvent, error) {
+	if c.Event != EventProject {
 		return nil, fmt.Errorf("not 'project' event")
 	}
 	var i github.ProjectEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetProjectCard returns information about a current project card.
 func GetProjectCard() (*github.ProjectCardEvent, error) {
-	if Event != EventProjectCard {
+	return current().GetProjectCard()
+}
+
+// GetProjectCard returns information about a current project card.
+func (c *Context) GetProjectCard() (*github.ProjectCardEvent, error) {
+	if c.Event != EventProjectCard {
 		return nil, fmt.Errorf("not 'project_card' event")
 	}
 	var i github.ProjectCardEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetPublic returns information about a current public.
 func GetPublic() (*github.PublicEvent, error) {
-	if Event != EventPublic {
+	return current().GetPublic()
+}
+
+// GetPublic returns information about a current public.
+func (c *Context) GetPublic() (*github.PublicEvent, error) {
+	if c.Event != EventPublic {
 		return nil, fmt.Errorf("not 'public' event")
 	}
 	var i github.PublicEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetPullRequest returns information about a current pull request.
 func GetPullRequest() (*github.PullRequestEvent, error) {
-	if Event != EventPullRequest {
+	return current().GetPullRequest()
+}
+
+// GetPullRequest returns information about a current pull request.
+func (c *Context) GetPullRequest() (*github.PullRequestEvent, error) {
+	if c.Event != EventPullRequest {
 		return nil, fmt.Errorf("not 'pull_request' event")
 	}
 	var i github.PullRequestEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetPullRequestReview returns information about a current pull request review.
 func GetPullRequestReview() (*github.PullRequestReviewEvent, error) {
-	if Event != EventPullRequestReview {
+	return current().GetPullRequestReview()
+}
+
+// GetPullRequestReview returns information about a current pull request review.
+func (c *Context) GetPullRequestReview() (*github.PullRequestReviewEvent, error) {
+	if c.Event != EventPullRequestReview {
 		return nil, fmt.Errorf("not 'pull_request_review' event")
 	}
 	var i github.PullRequestReviewEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetPullRequestReviewComment returns information about a current pull request review comment.
 func GetPullRequestReviewComment() (*github.PullRequestReviewCommentEvent, error) {
-	if Event != EventPullRequestReviewComment {
+	return current().GetPullRequestReviewComment()
+}
+
+// GetPullRequestReviewComment returns information about a current pull request review comment.
+func (c *Context) GetPullRequestReviewComment() (*github.PullRequestReviewCommentEvent, error) {
+	if c.Event != EventPullRequestReviewComment {
 		return nil, fmt.Errorf("not 'pull_request_review_comment' event")
 	}
 	var i github.PullRequestReviewCommentEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
+	return &i, err
+}
+
+// GetPullRequestTarget returns information about a current pull request target.
+func GetPullRequestTarget() (*github.PullRequestEvent, error) {
+	return current().GetPullRequestTarget()
+}
+
+// GetPullRequestTarget returns information about a current pull request target.
+func (c *Context) GetPullRequestTarget() (*github.PullRequestEvent, error) {
+	if c.Event != EventPullRequestTarget {
+		return nil, fmt.Errorf("not 'pull_request_target' event")
+	}
+	var i github.PullRequestEvent
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetPush returns information about a current push.
 func GetPush() (*github.PushEvent, error) {
-	if Event != EventPush {
+	return current().GetPush()
+}
+
+// GetPush returns information about a current push.
+func (c *Context) GetPush() (*github.PushEvent, error) {
+	if c.Event != EventPush {
 		return nil, fmt.Errorf("not 'push' event")
 	}
 	var i github.PushEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetRelease returns information about a current release.
 func GetRelease() (*github.ReleaseEvent, error) {
-	if Event != EventRelease {
+	return current().GetRelease()
+}
+
+// GetRelease returns information about a current release.
+func (c *Context) GetRelease() (*github.ReleaseEvent, error) {
+	if c.Event != EventRelease {
 		return nil, fmt.Errorf("not 'release' event")
 	}
 	var i github.ReleaseEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetStatus returns information about a current status.
 func GetStatus() (*github.StatusEvent, error) {
-	if Event != EventStatus {
+	return current().GetStatus()
+}
+
+// GetStatus returns information about a current status.
+func (c *Context) GetStatus() (*github.StatusEvent, error) {
+	if c.Event != EventStatus {
 		return nil, fmt.Errorf("not 'status' event")
 	}
 	var i github.StatusEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetWatch returns information about a current watch.
 func GetWatch() (*github.WatchEvent, error) {
-	if Event != EventWatch {
+	return current().GetWatch()
+}
+
+// GetWatch returns information about a current watch.
+func (c *Context) GetWatch() (*github.WatchEvent, error) {
+	if c.Event != EventWatch {
 		return nil, fmt.Errorf("not 'watch' event")
 	}
 	var i github.WatchEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
 // GetRepositoryDispatch returns information about a current repository dispatch.
 func GetRepositoryDispatch() (*github.RepositoryDispatchEvent, error) {
-	if Event != EventRepositoryDispatch {
+	return current().GetRepositoryDispatch()
+}
+
+// GetRepositoryDispatch returns information about a current repository dispatch.
+func (c *Context) GetRepositoryDispatch() (*github.RepositoryDispatchEvent, error) {
+	if c.Event != EventRepositoryDispatch {
 		return nil, fmt.Errorf("not 'repository_dispatch' event")
 	}
 	var i github.RepositoryDispatchEvent
-	err := decodeEventInfo(&i)
+	err := c.decodeEventInfo(&i)
 	return &i, err
 }
 
-func decodeEventInfo(i interface{}) error {
-	f, err := os.Open(eventPath)
+func (c *Context) decodeEventInfo(i interface{}) error {
+	f, err := os.Open(c.eventPath)
 	if err != nil {
 		return err
 	}
@@ -247,6 +247,19 @@ func TestGetPullRequestReviewComment(t *testing.T) {
 	t.Log(out.String())
 }
 
+func TestGetPullRequestTarget(t *testing.T) {
+	if Event != EventPullRequestTarget {
+		t.Skipf("Only applicatble for 'pull request target'")
+	}
+	event, err := GetPullRequestTarget()
+	assert.NoError(t, err)
+
+	var out bytes.Buffer
+	err = json.NewEncoder(&out).Encode(event)
+	require.NoError(t, err)
+	t.Log(out.String())
+}
+
 func TestGetPush(t *testing.T) {
 	if Event != EventPush {
 		t.Skipf("Only applicatble for 'push'")
@@ -29,7 +29,9 @@ the Go code and creating this file automatically for you.
 
 The main package inputs should be defined with the standard `flag` package for command line
 arguments, or by `os.Getenv` for environment variables. These inputs define the API of the program
-and `goaction` automatically detect them and creates the `action.yml` file from them.
+and `goaction` automatically detect them and creates the `action.yml` file from them. Inputs can
+also be read with the `goaction.Input` functions, which read the `INPUT_<NAME>` environment
+variables that Github sets for every action input.
 
 Additionally, goaction also provides a library that exposes all Github action environment in an
 easy-to-use API. See the documentation for more information.
@@ -88,6 +90,25 @@ container can also be built and tested manually:
 	$ docker build -t my-action .
 	$ docker run --rm my-action
 
+The action can also be run locally, the way that the Github runner runs it, with the goaction
+`run` subcommand. It runs the main package in CI mode with the given inputs, and prints the
+resulting outputs, environment changes, annotations and job summary:
+
+	$ goaction run -path . -input name=value
+
+Code that uses goaction can be unit tested in CI mode with the goactiontest package, which sets up a
+fake action environment in a Go test.
+
+The output of Go tools, such as `go vet`, can be converted to annotations on the pull request files
+with the annotate package, or with the goaction `annotate` subcommand:
+
+	$ go vet ./... 2>&1 | goaction annotate
+
+Similarly, test results can be reported with the testreport package, or with the goaction `report`
+subcommand, which annotates failing tests and adds a results table to the job summary:
+
+	$ go test -json ./... | goaction report
+
 Annotations
 
 Goaction parses Go script file and looks for annotations that extends the information that exists in
@@ -98,9 +119,14 @@ after slashes). They can only be set on a `var` definition. The following annota
 
 * `//goaction:skip` - skips an input out output definition.
 
-* `//goaction:description <description>` - add description for `os.Getenv`.
+* `//goaction:secret` - marks an input as a secret. The value of a secret input is automatically
+masked in the logs when the goaction package is initialized, and it is not shown in the
+`action.yml` file. The action must import the goaction package, or one of its packages, such as
+goaction/log. Otherwise, goaction fails to generate the action.
 
-* `//goaction:default <value>` - add default value for `os.Getenv`.
+* `//goaction:description <description>` - add description for `os.Getenv` and `goaction.Input`.
+
+* `//goaction:default <value>` - add default value for `os.Getenv` and `goaction.Input`.
 
 Using Goaction
 
@@ -113,11 +139,9 @@ not appear her).
 package goaction
 
 import (
-	"fmt"
 	"log"
 	"os"
-	"strconv"
-	"strings"
+	"regexp"
 )
 
 // Github actions default environment variables.
@@ -131,141 +155,285 @@ var (
 	//  if goaction.CI {
 	// 		// Code that should run only in Github action mode.
 	// 	}
-	CI = os.Getenv("CI") == "true"
+	CI = initial.CI
 	// The path to the GitHub home directory used to store user data. For example, /github/home.
-	Home = os.Getenv("HOME")
+	Home = initial.Home
 	// The name of the workflow
-	Workflow = os.Getenv("GITHUB_WORKFLOW")
+	Workflow = initial.Workflow
 	// 	A unique number for each run within a repository. This number does not change if you re-run
 	// the workflow run.
-	RunID = os.Getenv("GITHUB_RUN_ID")
+	RunID = initial.RunID
 	// 	A unique number for each run of a particular workflow in a repository. This number begins at
 	// 1 for the workflow's first run, and increments with each new run. This number does not change
 	// if you re-run the workflow run.
-	RunNum = os.Getenv("GITHUB_RUN_NUMBER")
+	RunNum = initial.RunNum
 	// The unique identifier (id) of the action.
-	ActionID = os.Getenv("GITHUB_ACTION")
+	ActionID = initial.ActionID
 	// The name of the person or app that initiated the workflow. For example, octocat.
-	Actor = os.Getenv("GITHUB_ACTOR")
+	Actor = initial.Actor
 	// The owner and repository name. For example, octocat/Hello-World.
-	Repository = os.Getenv("GITHUB_REPOSITORY")
+	Repository = initial.Repository
 	// The name of the webhook event that triggered the workflow.
-	Event = EventType(os.Getenv("GITHUB_EVENT_NAME"))
+	Event = initial.Event
 	// 	The GitHub workspace directory path. The workspace directory contains a subdirectory with a
 	// copy of your repository if your workflow uses the actions/checkout action. If you don't use
 	// the actions/checkout action, the directory will be empty. For example,
 	// /home/runner/work/my-repo-name/my-repo-name.
-	Workspace = os.Getenv("GITHUB_WORKSPACE")
+	Workspace = initial.Workspace
 	// The commit SHA that triggered the workflow. For example,
 	// ffac537e6cbbf934b08745a378932722df287a53.
-	SHA = os.Getenv("GITHUB_SHA")
+	SHA = initial.SHA
 	// The branch or tag ref that triggered the workflow. For example, refs/heads/feature-branch-1.
 	// If neither a branch or tag is available for the event type, the variable will not exist.
-	Ref = os.Getenv("GITHUB_REF")
+	Ref = initial.Ref
 	//	Only set for forked repositories. The branch of the head repository.
-	ForkedHeadRef = os.Getenv("GITHUB_HEAD_REF")
+	ForkedHeadRef = initial.ForkedHeadRef
 	// Only set for forked repositories. The branch of the base repository.
-	ForkedBaseRef = os.Getenv("GITHUB_BASE_REF")
-
-	eventPath = os.Getenv("GITHUB_EVENT_PATH")
-	envPath   = os.Getenv("GITHUB_ENV")
-
-	repoParts = strings.Split(Repository, "/")
+	ForkedBaseRef = initial.ForkedBaseRef
+	// The URL of the GitHub server. For example: https://github.com.
+	ServerURL = initial.ServerURL
+	// The URL of the REST API. For example: https://api.github.com.
+	APIURL = initial.APIURL
+	// The URL of the GraphQL API. For example: https://api.github.com/graphql.
+	GraphQLURL = initial.GraphQLURL
+	// The short ref name of the branch or tag that triggered the workflow run. For example,
+	// feature-branch-1. For pull requests, the format is <pr_number>/merge.
+	RefName = initial.RefName
+	// The type of ref that triggered the workflow run: RefBranch or RefTag. It is RefUnknown when
+	// not running in a workflow.
+	RefType = initial.RefType
+	// A unique number for each attempt of a particular workflow run in a repository. This number
+	// begins at 1 for the workflow run's first attempt, and increments with each re-run. It is 0
+	// when not running in a workflow.
+	RunAttempt = initial.RunAttempt
+	// The job_id of the current job. For example, greeting_job.
+	Job = initial.Job
+	// The username of the user that initiated the workflow run. If the workflow run is a re-run,
+	// this value may differ from Actor.
+	TriggeringActor = initial.TriggeringActor
+	// The ref path to the workflow. For example,
+	// octocat/hello-world/.github/workflows/my-workflow.yml@refs/heads/my_branch.
+	WorkflowRef = initial.WorkflowRef
+	// The ID of the repository. For example, 123456789. Note that this is different from the
+	// repository name. It is 0 when not running in a workflow.
+	RepositoryID = initial.RepositoryID
+	// The path where an action is located.
+	ActionPath = initial.ActionPath
+	// The operating system of the runner executing the job. Possible values are Linux, Windows, or
+	// macOS.
+	RunnerOS = initial.RunnerOS
+	// The architecture of the runner executing the job. Possible values are X86, X64, ARM, or
+	// ARM64.
+	RunnerArch = initial.RunnerArch
+	// The path to a temporary directory on the runner. This directory is emptied at the beginning
+	// and end of each job.
+	RunnerTemp = initial.RunnerTemp
+	// The path to the directory containing preinstalled tools for GitHub-hosted runners.
+	RunnerToolCache = initial.RunnerToolCache
+	// RunnerDebug is set to true when debug logging is enabled.
+	RunnerDebug = initial.RunnerDebug
+
+	eventPath   = initial.eventPath
+	envPath     = initial.envPath
+	outputPath  = initial.outputPath
+	pathPath    = initial.pathPath
+	summaryPath = initial.summaryPath
+	statePath   = initial.statePath
+	getenv      = initial.getenv
+	setenv      = initial.setenv
+	stdout      = initial.stdout
+
+	// The context that the package level variables are loaded from.
+	initial = FromEnv()
+
+	// Valid output names.
+	outputName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_-]*$`)
+	// Valid environment variable names.
+	envName = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
 )
 
 func init() {
 	if CI {
 		// Set the default logging to stdout since Github actions treats stderr as error level logs.
 		log.SetOutput(os.Stdout)
+		initial.maskSecrets()
+	}
+}
+
+// current returns the default context, as defined by the package level variables.
+func current() *Context {
+	return &Context{
+		CI:            CI,
+		Home:          Home,
+		Workflow:      Workflow,
+		RunID:         RunID,
+		RunNum:        RunNum,
+		ActionID:      ActionID,
+		Actor:         Actor,
+		Repository:    Repository,
+		Event:         Event,
+		Workspace:     Workspace,
+		SHA:           SHA,
+		Ref:           Ref,
+		ForkedHeadRef: ForkedHeadRef,
+		ForkedBaseRef: ForkedBaseRef,
+
+		ServerURL:       ServerURL,
+		APIURL:          APIURL,
+		GraphQLURL:      GraphQLURL,
+		RefName:         RefName,
+		RefType:         RefType,
+		RunAttempt:      RunAttempt,
+		Job:             Job,
+		TriggeringActor: TriggeringActor,
+		WorkflowRef:     WorkflowRef,
+		RepositoryID:    RepositoryID,
+		ActionPath:      ActionPath,
+		RunnerOS:        RunnerOS,
+		RunnerArch:      RunnerArch,
+		RunnerTemp:      RunnerTemp,
+		RunnerToolCache: RunnerToolCache,
+		RunnerDebug:     RunnerDebug,
+
+		eventPath:   eventPath,
+		envPath:     envPath,
+		outputPath:  outputPath,
+		pathPath:    pathPath,
+		summaryPath: summaryPath,
+		statePath:   statePath,
+
+		getenv: getenv,
+		setenv: setenv,
+		stdout: stdout,
 	}
 }
 
+// Use sets the package level variables and functions to use the given context, and returns the
+// context that was used before. It is useful for testing code that uses the package level API, see
+// the goactiontest package.
+func Use(c *Context) (prev *Context) {
+	prev = current()
+
+	CI = c.CI
+	Home = c.Home
+	Workflow = c.Workflow
+	RunID = c.RunID
+	RunNum = c.RunNum
+	ActionID = c.ActionID
+	Actor = c.Actor
+	Repository = c.Repository
+	Event = c.Event
+	Workspace = c.Workspace
+	SHA = c.SHA
+	Ref = c.Ref
+	ForkedHeadRef = c.ForkedHeadRef
+	ForkedBaseRef = c.ForkedBaseRef
+
+	ServerURL = c.ServerURL
+	APIURL = c.APIURL
+	GraphQLURL = c.GraphQLURL
+	RefName = c.RefName
+	RefType = c.RefType
+	RunAttempt = c.RunAttempt
+	Job = c.Job
+	TriggeringActor = c.TriggeringActor
+	WorkflowRef = c.WorkflowRef
+	RepositoryID = c.RepositoryID
+	ActionPath = c.ActionPath
+	RunnerOS = c.RunnerOS
+	RunnerArch = c.RunnerArch
+	RunnerTemp = c.RunnerTemp
+	RunnerToolCache = c.RunnerToolCache
+	RunnerDebug = c.RunnerDebug
+
+	eventPath = c.eventPath
+	envPath = c.envPath
+	outputPath = c.outputPath
+	pathPath = c.pathPath
+	summaryPath = c.summaryPath
+	statePath = c.statePath
+	getenv = c.getenv
+	setenv = c.setenv
+	stdout = c.stdout
+
+	return prev
+}
+
 // Setenv sets an environment variable that will only be visible for all following Github actions in
-// the current workflow, but not in the current action.
+// the current workflow, but not in the current action. The value may contain multiple lines.
 // See  https://docs.github.com/en/actions/reference/workflow-commands-for-github-actions#environment-files.
 func Setenv(name string, value string) error {
-	if !CI {
-		return nil
-	}
-	// Store in the given environment variable name such that programs that expect this environment
-	// variable (not through goaction) can get it.
-	f, err := os.OpenFile(envPath, os.O_APPEND|os.O_CREATE|os.O_RDWR, 0)
-	if err != nil {
-		return fmt.Errorf("can't open env file %s: %s", envPath, err)
-	}
-	defer f.Close()
-	_, err = fmt.Fprintf(f, "%s=%s\n", name, value)
-	if err != nil {
-		return fmt.Errorf("failed writing to env file: %s", err)
-	}
-	return nil
+	return current().Setenv(name, value)
 }
 
 // Export sets an environment variable that will also be visible for all following Github actions in
 // the current workflow.
 func Export(name string, value string) error {
-	err := os.Setenv(name, value)
-	if err != nil {
-		return err
-	}
-	return Setenv(name, value)
+	return current().Export(name, value)
 }
 
-// Output sets Github action output.
-// See https://help.github.com/en/actions/reference/workflow-commands-for-github-actions#setting-an-output-parameter.
-func Output(name string, value string, desc string) {
-	if !CI {
-		return
-	}
-	fmt.Printf("::set-output name=%s::%s\n", name, value)
+// Output sets Github action output. The value may contain multiple lines.
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#setting-an-output-parameter.
+func Output(name string, value string, desc string) error {
+	return current().Output(name, value, desc)
+}
+
+// OutputJSON sets Github action output to the JSON encoding of the given value. It can be decoded in
+// the workflow using the `fromJSON` function.
+func OutputJSON(name string, value interface{}, desc string) error {
+	return current().OutputJSON(name, value, desc)
 }
 
 // AddPath prepends a directory to the system PATH variable for all subsequent actions in the
 // current job. The currently running action cannot access the new path variable.
-// See https://help.github.com/en/actions/reference/workflow-commands-for-github-actions#adding-a-system-path.
-func AddPath(path string) {
-	if !CI {
-		return
-	}
-	fmt.Printf("::add-path::%s\n", path)
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#adding-a-system-path.
+func AddPath(path string) error {
+	return current().AddPath(path)
+}
+
+// AddPathNow prepends a directory to the system PATH variable for all subsequent actions in the
+// current job, and also to the PATH variable of the currently running action.
+func AddPathNow(path string) error {
+	return current().AddPathNow(path)
 }
 
 // Owner returns the name of the owner of the Github repository.
 func Owner() string {
-	if len(repoParts) < 2 {
-		return ""
-	}
-	return repoParts[0]
+	return current().Owner()
 }
 
 // Project returns the name of the project of the Github repository.
 func Project() string {
-	if len(repoParts) < 2 {
-		return ""
-	}
-	return repoParts[1]
+	return current().Project()
 }
 
-// Branch returns the push branch for push flow or empty string for other flows.
+// Branch returns the branch name when the workflow was triggered by a branch, or an empty string
+// otherwise. Slashes in the branch name are kept.
 func Branch() string {
-	return strings.Split(Ref, "/")[2]
+	return current().Branch()
 }
 
-// PrNum returns pull request number for PR flow or -1 in other flows.
-func PrNum() int {
-	if Event == EventPullRequest {
-		// Ref is in the form: "refs/pull/:prNumber/merge"
-		// See https://help.github.com/en/actions/reference/events-that-trigger-workflows#pull-request-event-pull_request
-		num, err := strconv.Atoi(strings.Split(Ref, "/")[2])
-		if err != nil {
-			panic(err) // Should not happen
-		}
-		return num
-	}
-	return -1
+// Tag returns the tag name when the workflow was triggered by a tag, or an empty string otherwise.
+func Tag() string {
+	return current().Tag()
 }
 
 // IsForked return true if the action is running on a forked repository.
 func IsForked() bool {
-	return ForkedBaseRef != ""
+	return current().IsForked()
+}
+
+// RepositoryURL returns the URL of the Github repository.
+func RepositoryURL() string {
+	return current().RepositoryURL()
+}
+
+// RunURL returns the URL of the current workflow run.
+func RunURL() string {
+	return current().RunURL()
+}
+
+// CommitURL returns the URL of the commit that triggered the workflow.
+func CommitURL() string {
+	return current().CommitURL()
 }
@@ -1,10 +1,16 @@
 package goaction
 
 import (
+	"io/ioutil"
+	"os"
+	"regexp"
 	"testing"
 
+	"github.com/posener/goaction/internal/envfile"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
+
 func TestVars(t *testing.T) {
 	if !CI {
 		t.Skip("Only runs in CI mode")
@@ -23,10 +29,84 @@ func TestVars(t *testing.T) {
 
 	assert.Equal(t, "posener", Owner())
 	assert.Equal(t, "goaction", Project())
-	switch Event{
+	switch Event {
 	case EventPush:
 		assert.Equal(t, "master", Branch())
 	case EventPullRequest:
 		assert.Less(t, 0, PrNum())
 	}
-}
\ No newline at end of file
+}
+
+func TestOutput(t *testing.T) {
+	t.Parallel()
+
+	c, path, remove := testContext(t, "GITHUB_OUTPUT", nil)
+	defer remove()
+
+	require.NoError(t, c.Output("out", "line1\nline2", ""))
+	require.NoError(t, c.OutputJSON("json", map[string]int{"a": 1}, ""))
+	assert.Error(t, c.Output("invalid name", "value", ""))
+
+	assertEnvFile(t, readFile(t, path), envfile.Var{Name: "out", Value: "line1\nline2"}, envfile.Var{Name: "json", Value: `{"a":1}`})
+}
+
+func TestSetenv(t *testing.T) {
+	t.Parallel()
+
+	c, path, remove := testContext(t, "GITHUB_ENV", nil)
+	defer remove()
+
+	require.NoError(t, c.Setenv("SETENV", "line1\nINJECTED=value"))
+	assert.Error(t, c.Setenv("INVALID=NAME", "value"))
+	assert.Error(t, c.Setenv("", "value"))
+	assert.Error(t, c.Export("INVALID NAME", "value"))
+
+	assertEnvFile(t, readFile(t, path), envfile.Var{Name: "SETENV", Value: "line1\nINJECTED=value"})
+}
+
+func TestAddPath(t *testing.T) {
+	t.Parallel()
+
+	env := map[string]string{"PATH": "/bin"}
+	c, path, remove := testContext(t, "GITHUB_PATH", env)
+	defer remove()
+
+	require.NoError(t, c.AddPath("/foo/bin"))
+	require.NoError(t, c.AddPathNow("/bar/bin"))
+	assert.Error(t, c.AddPath("/foo\n/bar"))
+
+	assert.Equal(t, "/foo/bin\n/bar/bin\n", readFile(t, path))
+	assert.Equal(t, "/bar/bin"+string(os.PathListSeparator)+"/bin", env["PATH"])
+}
+
+// testContext returns a context in CI mode, which is loaded from the given environment variables,
+// with the fileVar environment file variable set to a new temporary file. The returned function
+// removes the file.
+func testContext(t *testing.T, fileVar string, env map[string]string) (c *Context, path string, remove func()) {
+	t.Helper()
+	f, err := ioutil.TempFile("", "goaction")
+	require.NoError(t, err)
+	f.Close()
+
+	if env == nil {
+		env = map[string]string{}
+	}
+	env["CI"] = "true"
+	env[fileVar] = f.Name()
+	return FromMap(env), f.Name(), func() { os.Remove(f.Name()) }
+}
+
+// assertEnvFile asserts that an environment file contains the given variables in the multiline
+// syntax, and that the closing delimiter of each variable is its opening delimiter.
+func assertEnvFile(t *testing.T, got string, vars ...envfile.Var) {
+	t.Helper()
+	pattern := "^"
+	for _, v := range vars {
+		pattern += regexp.QuoteMeta(v.Name) + `<<(ghadelimiter_[0-9a-f]{32})\n` + regexp.QuoteMeta(v.Value) + `\n(ghadelimiter_[0-9a-f]{32})\n`
+	}
+	m := regexp.MustCompile(pattern + "$").FindStringSubmatch(got)
+	require.NotNil(t, m, "unexpected content:\n%s", got)
+	for i, v := range vars {
+		assert.Equal(t, m[2*i+1], m[2*i+2], "delimiters of %s", v.Name)
+	}
+}
@@ -0,0 +1,334 @@
+// Package goactiontest provides a fake Github action environment for testing Goaction code.
+//
+// An environment is created inside a test, and it sets the goaction and log packages to run in CI
+// mode with temporary environment files, a chosen event and inputs. When the tested code finishes,
+// the outputs, exported variables, masked values and annotations that it made can be checked.
+//
+//	func TestAction(t *testing.T) {
+//		env := goactiontest.New(t,
+//			goactiontest.WithEvent(goaction.EventPush, `{"ref": "refs/heads/main"}`),
+//			goactiontest.WithInput("name", "value"),
+//		)
+//		defer env.Close()
+//
+//		run()
+//
+//		assert.Equal(t, "result", env.Outputs()["output"])
+//	}
+//
+// The environment replaces package level state, so tests that use it should not run in parallel.
+package goactiontest
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/posener/goaction"
+	"github.com/posener/goaction/internal/command"
+	"github.com/posener/goaction/internal/envfile"
+	"github.com/posener/goaction/internal/testhook"
+	"github.com/posener/goaction/log"
+)
+
+// Option configures the fake environment.
+type Option func(*config)
+
+type config struct {
+	event   goaction.EventType
+	payload string
+	fixture string
+	inputs  map[string]string
+	secrets []string
+	env     map[string]string
+}
+
+// WithEvent sets the event that triggered the action and its JSON payload. By default the event
+// is a push event with an empty payload.
+func WithEvent(event goaction.EventType, payload string) Option {
+	return func(c *config) {
+		c.event = event
+		c.payload = payload
+	}
+}
+
+// WithEventFile sets the event that triggered the action, and a path to a JSON file with its
+// payload. Payload examples can be found in https://github.com/octokit/webhooks.
+func WithEventFile(event goaction.EventType, path string) Option {
+	return func(c *config) {
+		c.event = event
+		c.fixture = path
+	}
+}
+
+// WithInput sets an action input.
+func WithInput(name, value string) Option {
+	return func(c *config) {
+		c.inputs[name] = value
+	}
+}
+
+// WithSecret sets an action input that was annotated with `//goaction:secret`. The value is masked
+// when the environment is created, as it is when an action starts.
+func WithSecret(name, value string) Option {
+	return func(c *config) {
+		c.inputs[name] = value
+		c.secrets = append(c.secrets, name)
+	}
+}
+
+// WithEnv sets an environment variable, for example `GITHUB_REF`. It overrides the default values
+// that the environment sets.
+func WithEnv(name, value string) Option {
+	return func(c *config) {
+		c.env[name] = value
+	}
+}
+
+// Env is a fake Github action environment.
+type Env struct {
+	t    testing.TB
+	dir  string
+	ctx  *goaction.Context
+	log  bytes.Buffer
+	prev *goaction.Context
+	out  io.Writer
+}
+
+// Annotation is an error, warning, notice or debug message that was logged.
+type Annotation struct {
+	// Level is the annotation level: "debug", "notice", "warning" or "error".
+	Level     string
+	Title     string
+	File      string
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
+	Message   string
+}
+
+// New creates a fake Github action environment and sets the goaction and log packages to use it.
+// Close should be called when the test ends in order to restore the previous environment.
+func New(t testing.TB, opts ...Option) *Env {
+	t.Helper()
+	cfg := config{
+		event:   goaction.EventPush,
+		payload: "{}",
+		inputs:  map[string]string{},
+		env:     map[string]string{},
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	if cfg.fixture != "" {
+		b, err := ioutil.ReadFile(cfg.fixture)
+		if err != nil {
+			t.Fatalf("Failed reading event payload: %s", err)
+		}
+		cfg.payload = string(b)
+	}
+
+	dir, err := ioutil.TempDir("", "goactiontest")
+	if err != nil {
+		t.Fatalf("Failed creating temporary directory: %s", err)
+	}
+	e := &Env{t: t, dir: dir}
+
+	env := map[string]string{
+		"CI":                 "true",
+		"GITHUB_ACTIONS":     "true",
+		"GITHUB_WORKFLOW":    "test",
+		"GITHUB_JOB":         "test",
+		"GITHUB_ACTION":      "test",
+		"GITHUB_RUN_ID":      "1",
+		"GITHUB_RUN_NUMBER":  "1",
+		"GITHUB_RUN_ATTEMPT": "1",
+		"GITHUB_ACTOR":       "goactiontest",
+		"GITHUB_REPOSITORY":  "goactiontest/goactiontest",
+		"GITHUB_EVENT_NAME":  string(cfg.event),
+		"GITHUB_WORKSPACE":   dir,
+		"GITHUB_SERVER_URL":  "https://github.com",
+		"GITHUB_API_URL":     "https://api.github.com",
+		"GITHUB_GRAPHQL_URL": "https://api.github.com/graphql",
+		"RUNNER_TEMP":        dir,
+	}
+	for name, file := range map[string]string{
+		"GITHUB_ENV":          "env",
+		"GITHUB_OUTPUT":       "output",
+		"GITHUB_PATH":         "path",
+		"GITHUB_STEP_SUMMARY": "summary",
+		"GITHUB_STATE":        "state",
+		"GITHUB_EVENT_PATH":   "event.json",
+	} {
+		env[name] = filepath.Join(dir, file)
+		content := ""
+		if name == "GITHUB_EVENT_PATH" {
+			content = cfg.payload
+		}
+		e.writeFile(env[name], content)
+	}
+	for name, value := range cfg.inputs {
+		env["INPUT_"+strings.ToUpper(strings.ReplaceAll(name, " ", "_"))] = value
+	}
+	if len(cfg.secrets) > 0 {
+		env["GOACTION_SECRETS"] = strings.Join(cfg.secrets, ",")
+	}
+	for name, value := range cfg.env {
+		env[name] = value
+	}
+
+	e.ctx = goaction.FromMap(env)
+	e.ctx.SetCommandOutput(&e.log)
+	e.prev = goaction.Use(e.ctx)
+	// The environment is a new step, with its own annotations budget and error count.
+	testhook.ResetLog()
+	e.out = log.Writer()
+	log.SetOutput(&e.log)
+
+	for _, name := range cfg.secrets {
+		log.MaskSecret(cfg.inputs[name])
+	}
+	return e
+}
+
+// Close flushes the logs, as an action should do before it exits, and restores the environment that
+// was used before the fake environment was created.
+func (e *Env) Close() {
+	e.t.Helper()
+	err := log.Flush()
+	if err != nil {
+		e.t.Errorf("Failed flushing logs: %s", err)
+	}
+	testhook.ResetLog()
+	goaction.Use(e.prev)
+	log.SetOutput(e.out)
+	os.RemoveAll(e.dir)
+}
+
+// Context returns the context of the fake environment.
+func (e *Env) Context() *goaction.Context {
+	return e.ctx
+}
+
+// Outputs returns the action outputs that were set.
+func (e *Env) Outputs() map[string]string {
+	return e.vars("GITHUB_OUTPUT")
+}
+
+// Exported returns the environment variables that were set for the following actions.
+func (e *Env) Exported() map[string]string {
+	return e.vars("GITHUB_ENV")
+}
+
+// States returns the states that were saved for the other phases of the action.
+func (e *Env) States() map[string]string {
+	return e.vars("GITHUB_STATE")
+}
+
+// Paths returns the directories that were added to the system PATH, in the order they were added.
+func (e *Env) Paths() []string {
+	var paths []string
+	for _, line := range strings.Split(e.readFile(e.ctx.Getenv("GITHUB_PATH")), "\n") {
+		if line != "" {
+			paths = append(paths, line)
+		}
+	}
+	return paths
+}
+
+// Summary returns the Markdown content of the job summary.
+func (e *Env) Summary() string {
+	return e.readFile(e.ctx.Getenv("GITHUB_STEP_SUMMARY"))
+}
+
+// Masked returns the values that were masked in the logs.
+func (e *Env) Masked() []string {
+	var masked []string
+	for _, cmd := range e.commands() {
+		if cmd.Name == "add-mask" {
+			masked = append(masked, cmd.Message)
+		}
+	}
+	return masked
+}
+
+// Annotations returns the error, warning, notice and debug messages that were logged.
+func (e *Env) Annotations() []Annotation {
+	var annotations []Annotation
+	for _, cmd := range e.commands() {
+		switch cmd.Name {
+		case "error", "warning", "notice", "debug":
+		default:
+			continue
+		}
+		line, _ := strconv.Atoi(cmd.Property("line"))
+		col, _ := strconv.Atoi(cmd.Property("col"))
+		endLine, _ := strconv.Atoi(cmd.Property("endLine"))
+		endCol, _ := strconv.Atoi(cmd.Property("endColumn"))
+		annotations = append(annotations, Annotation{
+			Level:     cmd.Name,
+			Title:     cmd.Property("title"),
+			File:      cmd.Property("file"),
+			Line:      line,
+			Column:    col,
+			EndLine:   endLine,
+			EndColumn: endCol,
+			Message:   cmd.Message,
+		})
+	}
+	return annotations
+}
+
+// Log returns the log output.
+func (e *Env) Log() string {
+	return e.log.String()
+}
+
+func (e *Env) commands() []command.Command {
+	var (
+		cmds   []command.Command
+		stream command.Stream
+	)
+	for _, line := range strings.Split(e.log.String(), "\n") {
+		if cmd, ok := stream.Parse(line); ok {
+			cmds = append(cmds, cmd)
+		}
+	}
+	return cmds
+}
+
+func (e *Env) vars(name string) map[string]string {
+	e.t.Helper()
+	vars, err := envfile.ParseFile(e.ctx.Getenv(name))
+	if err != nil {
+		e.t.Fatalf("Failed parsing %s: %s", name, err)
+	}
+	m := map[string]string{}
+	for _, v := range vars {
+		m[v.Name] = v.Value
+	}
+	return m
+}
+
+func (e *Env) readFile(path string) string {
+	e.t.Helper()
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		e.t.Fatalf("Failed reading %s: %s", path, err)
+	}
+	return string(b)
+}
+
+func (e *Env) writeFile(path, content string) {
+	e.t.Helper()
+	err := ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		e.t.Fatalf("Failed writing %s: %s", path, err)
+	}
+}
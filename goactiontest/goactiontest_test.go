@@ -0,0 +1,95 @@
+package goactiontest
+
+import (
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/posener/goaction"
+	"github.com/posener/goaction/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnv(t *testing.T) {
+	oldCI, oldOut := goaction.CI, log.Writer()
+
+	env := New(t,
+		WithEvent(goaction.EventPullRequest, `{"number": 42}`),
+		WithInput("name", "value"),
+		WithSecret("token", "secret"),
+		WithEnv("GITHUB_REF", "refs/pull/42/merge"),
+	)
+
+	assert.True(t, goaction.CI)
+	assert.Equal(t, goaction.EventPullRequest, goaction.Event)
+	assert.Equal(t, "value", goaction.Input("name"))
+	assert.Equal(t, 42, goaction.PrNum())
+
+	require.NoError(t, goaction.Output("out", "line1\nline2", ""))
+	require.NoError(t, goaction.Setenv("FOO", "bar"))
+	require.NoError(t, goaction.SaveState("state", "saved"))
+	require.NoError(t, goaction.AddPath("/foo/bin"))
+	require.NoError(t, goaction.NewSummary().Heading(1, "Summary").Write())
+	log.Printf("printf")
+	log.Warnf("warnf")
+	log.ErrorfFile(token.Position{Filename: "foo.go", Line: 10, Column: 3}, "errorf")
+	log.NoticefAt(log.Annotation{
+		Title: "title",
+		Start: token.Position{Filename: "foo.go", Line: 10, Column: 3},
+		End:   token.Position{Line: 12, Column: 5},
+	}, "noticef")
+
+	assert.Equal(t, map[string]string{"out": "line1\nline2"}, env.Outputs())
+	assert.Equal(t, map[string]string{"FOO": "bar"}, env.Exported())
+	assert.Equal(t, map[string]string{"state": "saved"}, env.States())
+	assert.Equal(t, []string{"/foo/bin"}, env.Paths())
+	assert.Equal(t, "# Summary\n\n", env.Summary())
+	assert.Equal(t, []string{"secret", "c2VjcmV0"}, env.Masked())
+	assert.Equal(t, []Annotation{
+		{Level: "warning", Message: "warnf"},
+		{Level: "error", File: "foo.go", Line: 10, Column: 3, Message: "errorf"},
+		{Level: "notice", Title: "title", File: "foo.go", Line: 10, Column: 3, EndLine: 12, EndColumn: 5, Message: "noticef"},
+	}, env.Annotations())
+	assert.Contains(t, env.Log(), "printf\n")
+
+	env.Close()
+	assert.Equal(t, oldCI, goaction.CI)
+	assert.Equal(t, oldOut, log.Writer())
+}
+
+func TestWithEventFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "goactiontest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "event.json")
+	require.NoError(t, ioutil.WriteFile(path, []byte(`{"ref": "refs/heads/main"}`), 0644))
+
+	env := New(t, WithEventFile(goaction.EventPush, path))
+	defer env.Close()
+
+	push, err := goaction.GetPush()
+	require.NoError(t, err)
+	assert.Equal(t, "refs/heads/main", push.GetRef())
+}
+
+func TestSequentialEnvs(t *testing.T) {
+	env := New(t)
+	log.FailOnErrors(true)
+	for i := 0; i < 11; i++ {
+		log.Errorf("error %d", i)
+	}
+	assert.Equal(t, 11, log.ErrorCount())
+	env.Close()
+
+	// The log state of the previous environment does not carry over.
+	env = New(t)
+	defer env.Close()
+	assert.Equal(t, 0, log.ErrorCount())
+	log.ExitIfErrors()
+	log.Exit()
+	log.Errorf("error")
+	assert.Equal(t, "::error::error\n", env.Log())
+}
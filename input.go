@@ -0,0 +1,147 @@
+package goaction
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/posener/goaction/internal/command"
+)
+
+// Input returns the value of an action input, as given in the `with` section of the workflow step.
+// Github passes action inputs in INPUT_<NAME> environment variables. Goaction detects calls to the
+// Input functions and adds them as inputs to the action.yml file.
+func Input(name string) string {
+	return current().Input(name)
+}
+
+// InputRequired returns the value of an action input, or an error if it is empty.
+func InputRequired(name string) (string, error) {
+	return current().InputRequired(name)
+}
+
+// InputBool returns the boolean value of an action input. Like the official actions toolkit, it
+// follows the YAML 1.2 "Core Schema": true, True, TRUE, false, False and FALSE are accepted.
+//
+// An empty optional input is false, without an error: Github passes an input that was not given
+// and has no default as an empty value, and it should behave as an unset flag. This is also how
+// InputInt and InputDuration treat empty inputs. An empty input that was annotated with
+// `//goaction:required` is an error, like in the official actions toolkit.
+func InputBool(name string) (bool, error) {
+	return current().InputBool(name)
+}
+
+// InputInt returns the integer value of an action input. An empty input is 0.
+func InputInt(name string) (int, error) {
+	return current().InputInt(name)
+}
+
+// InputDuration returns the duration value of an action input, in the format of
+// time.ParseDuration. An empty input is 0.
+func InputDuration(name string) (time.Duration, error) {
+	return current().InputDuration(name)
+}
+
+// InputList returns the values of an action input which is separated by newlines or commas. Values
+// are trimmed and empty values are omitted.
+func InputList(name string) []string {
+	return current().InputList(name)
+}
+
+// Input returns the value of an action input. See Input.
+func (c *Context) Input(name string) string {
+	env := "INPUT_" + strings.ToUpper(strings.ReplaceAll(name, " ", "_"))
+	return strings.TrimSpace(c.getenv(env))
+}
+
+// InputRequired returns the value of an action input, or an error if it is empty.
+func (c *Context) InputRequired(name string) (string, error) {
+	v := c.Input(name)
+	if v == "" {
+		return "", fmt.Errorf("input required and not supplied: %s", name)
+	}
+	return v, nil
+}
+
+// InputBool returns the boolean value of an action input. An empty optional input is false. See
+// InputBool.
+func (c *Context) InputBool(name string) (bool, error) {
+	switch v := c.Input(name); v {
+	case "true", "True", "TRUE":
+		return true, nil
+	case "false", "False", "FALSE":
+		return false, nil
+	case "":
+		if c.required(name) {
+			return false, fmt.Errorf("input required and not supplied: %s", name)
+		}
+		return false, nil
+	default:
+		return false, fmt.Errorf("input %s: %q is not a boolean value according to YAML 1.2 core schema (true, True, TRUE, false, False, FALSE)", name, v)
+	}
+}
+
+// InputInt returns the integer value of an action input. An empty input is 0.
+func (c *Context) InputInt(name string) (int, error) {
+	v := c.Input(name)
+	if v == "" {
+		return 0, nil
+	}
+	i, err := strconv.Atoi(v)
+	if err != nil {
+		return 0, fmt.Errorf("input %s: %s", name, err)
+	}
+	return i, nil
+}
+
+// InputDuration returns the duration value of an action input. An empty input is 0.
+func (c *Context) InputDuration(name string) (time.Duration, error) {
+	v := c.Input(name)
+	if v == "" {
+		return 0, nil
+	}
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return 0, fmt.Errorf("input %s: %s", name, err)
+	}
+	return d, nil
+}
+
+// InputList returns the values of an action input which is separated by newlines or commas.
+func (c *Context) InputList(name string) []string {
+	var values []string
+	for _, v := range strings.FieldsFunc(c.Input(name), func(r rune) bool { return r == '\n' || r == ',' }) {
+		v = strings.TrimSpace(v)
+		if v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// required returns whether an action input was annotated with `//goaction:required`. The names of
+// the required inputs that are read by the Input functions are passed by the goaction generated
+// action file in the GOACTION_REQUIRED environment variable.
+func (c *Context) required(name string) bool {
+	for _, required := range strings.Split(c.getenv("GOACTION_REQUIRED"), ",") {
+		if required == name {
+			return true
+		}
+	}
+	return false
+}
+
+// maskSecrets masks the values of the action inputs which were annotated with
+// `//goaction:secret`. The names of these inputs are passed by the goaction generated action file
+// in the GOACTION_SECRETS environment variable.
+func (c *Context) maskSecrets() {
+	for _, name := range strings.Split(c.getenv("GOACTION_SECRETS"), ",") {
+		if name == "" {
+			continue
+		}
+		for _, cmd := range command.MaskSecret(c.Input(name)) {
+			fmt.Fprintln(c.stdout, cmd)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package goaction
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInput(t *testing.T) {
+	t.Parallel()
+
+	c := FromMap(map[string]string{
+		"INPUT_STRING":       "  value ",
+		"INPUT_WITH_SPACE":   "space",
+		"INPUT_BOOL-TRUE":    "True",
+		"INPUT_BOOL-FALSE":   "FALSE",
+		"INPUT_BOOL-INVALID": "yes",
+		"INPUT_BOOL-EMPTY":   " ",
+		"INPUT_INT":          "42",
+		"INPUT_INT-INVALID":  "4.2",
+		"INPUT_DURATION":     "1m30s",
+		"INPUT_LIST":         "a, b\nc\n\n,d",
+		"GOACTION_REQUIRED":  "bool-required,bool-true",
+	})
+
+	assert.Equal(t, "value", c.Input("string"))
+	assert.Equal(t, "space", c.Input("with space"))
+	assert.Equal(t, "", c.Input("missing"))
+
+	v, err := c.InputRequired("string")
+	require.NoError(t, err)
+	assert.Equal(t, "value", v)
+	_, err = c.InputRequired("missing")
+	assert.Error(t, err)
+
+	b, err := c.InputBool("bool-true")
+	require.NoError(t, err)
+	assert.True(t, b)
+	b, err = c.InputBool("bool-false")
+	require.NoError(t, err)
+	assert.False(t, b)
+	b, err = c.InputBool("missing")
+	require.NoError(t, err)
+	assert.False(t, b)
+	b, err = c.InputBool("bool-empty")
+	require.NoError(t, err)
+	assert.False(t, b)
+	_, err = c.InputBool("bool-invalid")
+	assert.Error(t, err)
+	_, err = c.InputBool("bool-required")
+	assert.EqualError(t, err, "input required and not supplied: bool-required")
+
+	i, err := c.InputInt("int")
+	require.NoError(t, err)
+	assert.Equal(t, 42, i)
+	_, err = c.InputInt("int-invalid")
+	assert.Error(t, err)
+
+	d, err := c.InputDuration("duration")
+	require.NoError(t, err)
+	assert.Equal(t, 90*time.Second, d)
+
+	assert.Equal(t, []string{"a", "b", "c", "d"}, c.InputList("list"))
+	assert.Nil(t, c.InputList("missing"))
+}
+
+func TestMaskSecrets(t *testing.T) {
+	t.Parallel()
+
+	c := FromMap(map[string]string{
+		"GOACTION_SECRETS": "token,missing",
+		"INPUT_TOKEN":      "secret",
+	})
+	var b bytes.Buffer
+	c.SetCommandOutput(&b)
+
+	c.maskSecrets()
+	assert.Equal(t, "::add-mask::secret\n::add-mask::c2VjcmV0\n", b.String())
+}
@@ -0,0 +1,126 @@
+// Package command handles Github action workflow commands.
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions.
+package command
+
+import (
+	"strings"
+)
+
+// Command is a workflow command, of the form:
+//
+//	::{name} {key}={value},{key}={value}::{message}
+type Command struct {
+	Name       string
+	Properties []Property
+	Message    string
+}
+
+// Property is a key and a value of a workflow command property.
+type Property struct {
+	Key   string
+	Value string
+}
+
+var (
+	// Escaping of command messages and property values. See the escaping rules in
+	// https://github.com/actions/toolkit/blob/main/packages/core/src/command.ts.
+	dataEscaper     = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A")
+	propertyEscaper = strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C")
+	unescaper       = strings.NewReplacer("%25", "%", "%0D", "\r", "%0A", "\n", "%3A", ":", "%2C", ",")
+)
+
+// New returns a new command.
+func New(name string, message string, properties ...Property) Command {
+	return Command{Name: name, Properties: properties, Message: message}
+}
+
+// String returns the encoded command, which can be printed to the action output. The message and
+// the property values are escaped, and properties with empty values are omitted.
+func (c Command) String() string {
+	var b strings.Builder
+	b.WriteString("::")
+	b.WriteString(c.Name)
+	sep := " "
+	for _, p := range c.Properties {
+		if p.Value == "" {
+			continue
+		}
+		b.WriteString(sep)
+		b.WriteString(p.Key)
+		b.WriteString("=")
+		b.WriteString(propertyEscaper.Replace(p.Value))
+		sep = ","
+	}
+	b.WriteString("::")
+	b.WriteString(dataEscaper.Replace(c.Message))
+	return b.String()
+}
+
+// Property returns the value of a command property, or an empty string if it does not exist.
+func (c Command) Property(key string) string {
+	for _, p := range c.Properties {
+		if p.Key == key {
+			return p.Value
+		}
+	}
+	return ""
+}
+
+// Parse parses a workflow command from a line of output. It returns false if the line is not a
+// workflow command. The message and the property values are unescaped.
+func Parse(line string) (Command, bool) {
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	if !strings.HasPrefix(line, "::") {
+		return Command{}, false
+	}
+	end := strings.Index(line[2:], "::")
+	if end < 0 {
+		return Command{}, false
+	}
+	cmd, msg := line[2:2+end], line[2+end+2:]
+	name, props := cmd, ""
+	if i := strings.Index(cmd, " "); i >= 0 {
+		name, props = cmd[:i], cmd[i+1:]
+	}
+	if name == "" {
+		return Command{}, false
+	}
+	c := Command{Name: name, Message: unescaper.Replace(msg)}
+	for _, prop := range strings.Split(props, ",") {
+		if prop == "" {
+			continue
+		}
+		kv := strings.SplitN(prop, "=", 2)
+		if len(kv) != 2 {
+			continue
+		}
+		c.Properties = append(c.Properties, Property{Key: kv[0], Value: unescaper.Replace(kv[1])})
+	}
+	return c, true
+}
+
+// Stream parses workflow commands from the lines of an action output, as the runner does. Commands
+// that are printed while workflow commands are stopped are ignored.
+type Stream struct {
+	stopToken string
+}
+
+// Parse parses a workflow command from a line of output. It returns false if the line is not a
+// workflow command, or if workflow commands are stopped.
+func (s *Stream) Parse(line string) (Command, bool) {
+	c, ok := Parse(line)
+	if !ok {
+		return Command{}, false
+	}
+	if s.stopToken != "" {
+		if c.Name == s.stopToken {
+			s.stopToken = ""
+		}
+		return Command{}, false
+	}
+	if c.Name == "stop-commands" {
+		s.stopToken = c.Message
+	}
+	return c, true
+}
@@ -0,0 +1,173 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		line   string
+		want   Command
+		wantOK bool
+	}{
+		{line: "plain text"},
+		{line: "::not a command"},
+		{line: "::::message"},
+		{
+			line:   "::debug::message",
+			want:   Command{Name: "debug", Message: "message"},
+			wantOK: true,
+		},
+		{
+			line: "::error file=foo.go,line=10,col=3::message::with colons\n",
+			want: Command{
+				Name:       "error",
+				Properties: []Property{{Key: "file", Value: "foo.go"}, {Key: "line", Value: "10"}, {Key: "col", Value: "3"}},
+				Message:    "message::with colons",
+			},
+			wantOK: true,
+		},
+		{
+			line: "::error file=a%2Cb%3Ac.go::line1%0D%0Aline2 100%25\n",
+			want: Command{
+				Name:       "error",
+				Properties: []Property{{Key: "file", Value: "a,b:c.go"}},
+				Message:    "line1\r\nline2 100%",
+			},
+			wantOK: true,
+		},
+		{
+			line:   "::add-mask::",
+			want:   Command{Name: "add-mask"},
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, ok := Parse(tt.line)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestProperty(t *testing.T) {
+	t.Parallel()
+
+	c := Command{Properties: []Property{{Key: "file", Value: "foo.go"}}}
+	assert.Equal(t, "foo.go", c.Property("file"))
+	assert.Equal(t, "", c.Property("line"))
+}
+
+func TestString(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		cmd  Command
+		want string
+	}{
+		{
+			name: "no properties",
+			cmd:  New("debug", "message"),
+			want: "::debug::message",
+		},
+		{
+			name: "properties",
+			cmd:  New("error", "message", Property{Key: "file", Value: "foo.go"}, Property{Key: "line", Value: "10"}),
+			want: "::error file=foo.go,line=10::message",
+		},
+		{
+			name: "empty properties",
+			cmd:  New("error", "message", Property{Key: "file", Value: ""}, Property{Key: "line", Value: "10"}),
+			want: "::error line=10::message",
+		},
+		{
+			name: "message percent",
+			cmd:  New("debug", "100%"),
+			want: "::debug::100%25",
+		},
+		{
+			name: "message newlines",
+			cmd:  New("debug", "line1\r\nline2\n"),
+			want: "::debug::line1%0D%0Aline2%0A",
+		},
+		{
+			name: "message colon and comma",
+			cmd:  New("debug", "a: b, c::d"),
+			want: "::debug::a: b, c::d",
+		},
+		{
+			name: "property percent",
+			cmd:  New("error", "", Property{Key: "file", Value: "100%.go"}),
+			want: "::error file=100%25.go::",
+		},
+		{
+			name: "property newlines",
+			cmd:  New("error", "", Property{Key: "title", Value: "a\r\nb"}),
+			want: "::error title=a%0D%0Ab::",
+		},
+		{
+			name: "property colon and comma",
+			cmd:  New("error", "", Property{Key: "file", Value: "a,b:c.go"}),
+			want: "::error file=a%2Cb%3Ac.go::",
+		},
+		{
+			name: "escaped sequence",
+			cmd:  New("error", "%0A", Property{Key: "file", Value: "%2C"}),
+			want: "::error file=%252C::%250A",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.cmd.String()
+			assert.Equal(t, tt.want, got)
+
+			// Parsing the encoded command should return the original command.
+			parsed, ok := Parse(got)
+			assert.True(t, ok)
+			assert.Equal(t, tt.cmd.Message, parsed.Message)
+			for _, p := range tt.cmd.Properties {
+				assert.Equal(t, p.Value, parsed.Property(p.Key))
+			}
+		})
+	}
+}
+
+func TestStream(t *testing.T) {
+	t.Parallel()
+
+	var s Stream
+	var got []string
+	for _, line := range []string{
+		"::debug::1",
+		"::stop-commands::token",
+		"::debug::2",
+		"::other::",
+		"::token::",
+		"::debug::3",
+	} {
+		if c, ok := s.Parse(line); ok {
+			got = append(got, c.Name+":"+c.Message)
+		}
+	}
+	assert.Equal(t, []string{"debug:1", "stop-commands:token", "debug:3"}, got)
+}
+
+func TestMaskSecret(t *testing.T) {
+	t.Parallel()
+
+	var got []string
+	for _, c := range MaskSecret("a b\n\nc") {
+		assert.Equal(t, "add-mask", c.Name)
+		got = append(got, c.Message)
+	}
+	assert.Equal(t, []string{"a b", "c", "a+b%0A%0Ac", "a%20b%0A%0Ac", "YSBiCgpj"}, got)
+	assert.Empty(t, MaskSecret(""))
+}
@@ -0,0 +1,47 @@
+package command
+
+import (
+	"encoding/base64"
+	"net/url"
+	"strings"
+)
+
+// Mask returns the add-mask commands that mask a term in the logs. Multi-line terms are masked line
+// by line, as the runner masks single lines.
+func Mask(term string) []Command {
+	var cmds []Command
+	for _, line := range strings.Split(term, "\n") {
+		line = strings.TrimSuffix(line, "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		cmds = append(cmds, New("add-mask", line))
+	}
+	return cmds
+}
+
+// MaskSecret returns the add-mask commands that mask a secret in the logs. In addition to the
+// secret itself, the URL encoded and base64 encoded forms of the secret are masked.
+func MaskSecret(secret string) []Command {
+	if secret == "" {
+		return nil
+	}
+	var (
+		cmds   []Command
+		masked = map[string]bool{}
+	)
+	for _, term := range []string{
+		secret,
+		url.QueryEscape(secret),
+		url.PathEscape(secret),
+		base64.StdEncoding.EncodeToString([]byte(secret)),
+		base64.URLEncoding.EncodeToString([]byte(secret)),
+	} {
+		if masked[term] {
+			continue
+		}
+		masked[term] = true
+		cmds = append(cmds, Mask(term)...)
+	}
+	return cmds
+}
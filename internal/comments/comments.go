@@ -10,6 +10,7 @@ import (
 var (
 	docRequired = regexp.MustCompile("^//goaction:required$")
 	docSkip     = regexp.MustCompile("^//goaction:skip$")
+	docSecret   = regexp.MustCompile("^//goaction:secret$")
 	docDefault  = regexp.MustCompile("^//goaction:default (.*)$")
 	docDesc     = regexp.MustCompile("^//goaction:description (.*)$")
 )
@@ -18,6 +19,7 @@ var (
 type Comments struct {
 	Required Bool
 	Skip     Bool
+	Secret   Bool
 	Default  String
 	Desc     String
 }
@@ -45,6 +47,8 @@ func (d *Comments) Parse(doc *ast.CommentGroup) {
 			d.Required = Bool{Value: true, Pos: pos}
 		case docSkip.MatchString(txt):
 			d.Skip = Bool{Value: true, Pos: pos}
+		case docSecret.MatchString(txt):
+			d.Secret = Bool{Value: true, Pos: pos}
 		case docDefault.MatchString(txt):
 			d.Default = String{Value: docDefault.FindStringSubmatch(txt)[1], Pos: pos}
 		case docDesc.MatchString(txt):
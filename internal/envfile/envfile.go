@@ -0,0 +1,141 @@
+// Package envfile writes and parses Github action environment files.
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#environment-files.
+package envfile
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// maxDelimiterAttempts limits the number of attempts to generate a delimiter that does not collide
+// with the written value.
+const maxDelimiterAttempts = 10
+
+// maxLineSize is the maximal size of a line when parsing environment files.
+const maxLineSize = 1024 * 1024
+
+// Append appends a name and a value to the environment file in the given path. The value is
+// written using the multiline syntax, which is safe for any value:
+//
+//	{name}<<{delimiter}
+//	{value}
+//	{delimiter}
+func Append(path, name, value string) error {
+	if path == "" {
+		return fmt.Errorf("environment file path is not set")
+	}
+	delim, err := delimiter(value)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("can't open file %s: %s", path, err)
+	}
+	defer f.Close()
+	_, err = fmt.Fprintf(f, "%s<<%s\n%s\n%s\n", name, delim, value, delim)
+	if err != nil {
+		return fmt.Errorf("failed writing to file %s: %s", path, err)
+	}
+	return nil
+}
+
+// AppendLine appends a single line to the file in the given path.
+func AppendLine(path, line string) error {
+	if path == "" {
+		return fmt.Errorf("environment file path is not set")
+	}
+	if strings.ContainsAny(line, "\r\n") {
+		return fmt.Errorf("line %q must not contain line breaks", line)
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("can't open file %s: %s", path, err)
+	}
+	defer f.Close()
+	_, err = fmt.Fprintln(f, line)
+	if err != nil {
+		return fmt.Errorf("failed writing to file %s: %s", path, err)
+	}
+	return nil
+}
+
+// delimiter returns a random heredoc delimiter which does not appear in the given value.
+func delimiter(value string) (string, error) {
+	b := make([]byte, 16)
+	for i := 0; i < maxDelimiterAttempts; i++ {
+		_, err := rand.Read(b)
+		if err != nil {
+			return "", fmt.Errorf("generating delimiter: %s", err)
+		}
+		delim := "ghadelimiter_" + hex.EncodeToString(b)
+		if !strings.Contains(value, delim) {
+			return delim, nil
+		}
+	}
+	return "", fmt.Errorf("failed generating delimiter that does not collide with value")
+}
+
+// Var is a name and a value in an environment file.
+type Var struct {
+	Name  string
+	Value string
+}
+
+// Parse parses the content of an environment file. It supports both the single line syntax,
+// `{name}={value}`, and the multiline syntax, as written by Append.
+func Parse(r io.Reader) ([]Var, error) {
+	var vars []Var
+	s := bufio.NewScanner(r)
+	s.Buffer(nil, maxLineSize)
+	for lineNum := 1; s.Scan(); lineNum++ {
+		line := s.Text()
+		if line == "" {
+			continue
+		}
+		// Multiline syntax.
+		if i := strings.Index(line, "<<"); i > 0 && !strings.Contains(line[:i], "=") {
+			name, delim := line[:i], line[i+2:]
+			var value []string
+			closed := false
+			for s.Scan() {
+				lineNum++
+				if s.Text() == delim {
+					closed = true
+					break
+				}
+				value = append(value, s.Text())
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: missing delimiter %q for %s", lineNum, delim, name)
+			}
+			vars = append(vars, Var{Name: name, Value: strings.Join(value, "\n")})
+			continue
+		}
+		// Single line syntax.
+		i := strings.Index(line, "=")
+		if i <= 0 {
+			return nil, fmt.Errorf("line %d: invalid format: %q", lineNum, line)
+		}
+		vars = append(vars, Var{Name: line[:i], Value: line[i+1:]})
+	}
+	return vars, s.Err()
+}
+
+// ParseFile parses the environment file in the given path. A missing file is considered empty.
+func ParseFile(path string) ([]Var, error) {
+	f, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
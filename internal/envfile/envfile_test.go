@@ -0,0 +1,115 @@
+package envfile
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppend(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "envfile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "env")
+
+	require.NoError(t, Append(path, "foo", "bar"))
+	require.NoError(t, Append(path, "multi", "line1\nline2"))
+
+	got, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+
+	want := regexp.MustCompile(`^foo<<(ghadelimiter_[0-9a-f]{32})\nbar\n(ghadelimiter_[0-9a-f]{32})\nmulti<<(ghadelimiter_[0-9a-f]{32})\nline1\nline2\n(ghadelimiter_[0-9a-f]{32})\n$`)
+	m := want.FindStringSubmatch(string(got))
+	require.NotNil(t, m, "unexpected content:\n%s", got)
+	assert.Equal(t, m[1], m[2])
+	assert.Equal(t, m[3], m[4])
+	assert.NotEqual(t, m[1], m[3])
+}
+
+func TestAppendNoPath(t *testing.T) {
+	t.Parallel()
+	assert.Error(t, Append("", "foo", "bar"))
+}
+
+func TestDelimiter(t *testing.T) {
+	t.Parallel()
+
+	d1, err := delimiter("")
+	require.NoError(t, err)
+	d2, err := delimiter(d1)
+	require.NoError(t, err)
+	assert.NotEqual(t, d1, d2)
+}
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	content := `single=value
+with=equal=sign
+
+multi<<EOF
+line1
+line2
+EOF
+empty<<EOF
+EOF
+`
+	got, err := Parse(strings.NewReader(content))
+	require.NoError(t, err)
+	want := []Var{
+		{Name: "single", Value: "value"},
+		{Name: "with", Value: "equal=sign"},
+		{Name: "multi", Value: "line1\nline2"},
+		{Name: "empty", Value: ""},
+	}
+	assert.Equal(t, want, got)
+}
+
+func TestParseAppended(t *testing.T) {
+	t.Parallel()
+
+	dir, err := ioutil.TempDir("", "envfile")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "env")
+
+	require.NoError(t, Append(path, "foo", "bar"))
+	require.NoError(t, Append(path, "multi", "line1\nline2"))
+
+	got, err := ParseFile(path)
+	require.NoError(t, err)
+	want := []Var{
+		{Name: "foo", Value: "bar"},
+		{Name: "multi", Value: "line1\nline2"},
+	}
+	assert.Equal(t, want, got)
+
+	got, err = ParseFile(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Empty(t, got)
+}
+
+func TestParseInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, content := range []string{
+		"no-equal-sign\n",
+		"=value\n",
+		"multi<<EOF\nline1\n",
+		// The closing delimiter must be the opening delimiter.
+		"multi<<EOF\nline1\nEOG\n",
+		"multi<<EOF\nline1\nEOF2\n",
+		"multi<<EOF\nline1\n EOF\n",
+	} {
+		_, err := Parse(strings.NewReader(content))
+		assert.Error(t, err, content)
+	}
+}
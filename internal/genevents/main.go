@@ -13,6 +13,9 @@ import (
 type event struct {
 	Name             string
 	SkipEventGetFunc bool
+	// GithubType overrides the name of the github event type, for events that share the payload
+	// of another event.
+	GithubType string
 }
 
 func (e event) CamelCase() string {
@@ -32,6 +35,9 @@ func (e event) EventGetFuncName() string {
 }
 
 func (e event) GithubReturnValue() string {
+	if e.GithubType != "" {
+		return "github." + e.GithubType + "Event"
+	}
 	return "github." + e.CamelCase() + "Event"
 }
 
@@ -46,6 +52,7 @@ var events = []event{
 	{Name: "issue_comment"},
 	{Name: "issues"},
 	{Name: "label"},
+	{Name: "merge_group", SkipEventGetFunc: true},
 	{Name: "milestone"},
 	{Name: "page_build"},
 	{Name: "project"},
@@ -54,6 +61,7 @@ var events = []event{
 	{Name: "pull_request"},
 	{Name: "pull_request_review"},
 	{Name: "pull_request_review_comment"},
+	{Name: "pull_request_target", GithubType: "PullRequest"},
 	{Name: "push"},
 	{Name: "registry_package", SkipEventGetFunc: true},
 	{Name: "release"},
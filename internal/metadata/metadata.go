@@ -7,14 +7,30 @@ import (
 	"go/doc"
 	"go/token"
 	"strconv"
+	"strings"
 
 	"github.com/goccy/go-yaml"
 	"github.com/posener/goaction/internal/comments"
 )
 
+// SecretsEnv is the environment variable that holds a comma separated list of the secret inputs
+// names.
+const SecretsEnv = "GOACTION_SECRETS"
+
+// RequiredEnv is the environment variable that holds a comma separated list of the names of the
+// required inputs that are read by the goaction Input functions.
+const RequiredEnv = "GOACTION_REQUIRED"
+
+// goactionPkg is the import path of the goaction package, which masks the secret inputs when it is
+// initialized.
+const goactionPkg = "github.com/posener/goaction"
+
 const (
 	inputFlag = "flag"
 	inputEnv  = "env"
+	// Input that is read by goaction.Input functions. Github sets these inputs in INPUT_<NAME>
+	// environment variables, so they are not passed in the action args or env.
+	inputAction = "input"
 )
 
 type ErrParse struct {
@@ -45,7 +61,8 @@ type Input struct {
 	Desc     string      `yaml:"description,omitempty"`
 	Required bool
 
-	tp string
+	tp     string
+	secret bool
 }
 
 // Output for Github action.
@@ -59,8 +76,12 @@ type Output struct {
 type Runs struct {
 	Using string // Alwasy "docker"
 	Image string
-	Env   yaml.MapSlice `yaml:",omitempty"` // map[string]string
-	Args  []string      `yaml:",omitempty"`
+	// Entrypoint of the pre phase, runs before the main entrypoint.
+	PreEntrypoint string        `yaml:"pre-entrypoint,omitempty"`
+	Env           yaml.MapSlice `yaml:",omitempty"` // map[string]string
+	Args          []string      `yaml:",omitempty"`
+	// Entrypoint of the post phase, runs at the end of the job.
+	PostEntrypoint string `yaml:"post-entrypoint,omitempty"`
 }
 
 func New(pkg *ast.Package) (Metadata, error) {
@@ -92,6 +113,15 @@ func New(pkg *ast.Package) (Metadata, error) {
 	if err != nil {
 		return m, err
 	}
+	for i, mapItem := range m.Inputs {
+		in := mapItem.Value.(Input)
+		if in.secret {
+			// Secret values should not appear in the action file.
+			in.Default = nil
+			in.Desc = secretDesc(in.Desc)
+			m.Inputs[i].Value = in
+		}
+	}
 	m.Runs.Args, err = calcArgs(m.Inputs)
 	if err != nil {
 		return m, err
@@ -100,12 +130,84 @@ func New(pkg *ast.Package) (Metadata, error) {
 	if err != nil {
 		return m, err
 	}
+	if hasSecrets(m.Inputs) && !importsGoaction(pkg) {
+		return m, fmt.Errorf("secret inputs are masked by the %s package, which is not imported by the action", goactionPkg)
+	}
 
 	return m, nil
 }
 
-func (m *Metadata) AddInput(name string, in Input) {
+func hasSecrets(inputs yaml.MapSlice /* map[string]Input */) bool {
+	for _, mapItem := range inputs {
+		if mapItem.Value.(Input).secret {
+			return true
+		}
+	}
+	return false
+}
+
+// importsGoaction returns true if the package imports the goaction package, or one of its
+// packages, which all import the goaction package.
+func importsGoaction(pkg *ast.Package) bool {
+	for _, f := range pkg.Files {
+		for _, imp := range f.Imports {
+			path := unqoute(imp.Path.Value)
+			if path == goactionPkg || strings.HasPrefix(path, goactionPkg+"/") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// AddInput adds an input. An input can be read in more than one place, in which case the properties
+// of the input are merged. A property that is set in only one place applies to the input, and an
+// error is returned if the places disagree on the input type, default, description or whether it
+// is required.
+func (m *Metadata) AddInput(name string, in Input) error {
+	for i, mapItem := range m.Inputs {
+		if mapItem.Key.(string) != name {
+			continue
+		}
+		merged, err := mergeInputs(mapItem.Value.(Input), in)
+		if err != nil {
+			return fmt.Errorf("input %q: %s", name, err)
+		}
+		m.Inputs[i].Value = merged
+		return nil
+	}
 	m.Inputs = append(m.Inputs, yaml.MapItem{Key: name, Value: in})
+	return nil
+}
+
+// mergeInputs merges the properties of an input that is read in two places.
+func mergeInputs(a, b Input) (Input, error) {
+	if a.tp != b.tp {
+		return a, fmt.Errorf("read as %s and as %s", a.tp, b.tp)
+	}
+	if a.Required != b.Required {
+		return a, fmt.Errorf("required in only some of the places it is read")
+	}
+	if a.Default == nil {
+		a.Default = b.Default
+	} else if b.Default != nil && a.Default != b.Default {
+		return a, fmt.Errorf("different defaults: %v and %v", a.Default, b.Default)
+	}
+	if a.Desc == "" {
+		a.Desc = b.Desc
+	} else if b.Desc != "" && a.Desc != b.Desc {
+		return a, fmt.Errorf("different descriptions: %s and %s", a.Desc, b.Desc)
+	}
+	a.secret = a.secret || b.secret
+	return a, nil
+}
+
+// addInput adds an input that is read by a call, and panics with ErrParse if it can't be added.
+func (m *Metadata) addInput(call *ast.CallExpr, name string, in Input) {
+	err := m.AddInput(name, in)
+	if err != nil {
+		panic(ErrParse{Pos: call.Pos(), error: err})
+	}
 }
 
 func (m *Metadata) AddOutput(name string, out Output) {
@@ -178,81 +280,108 @@ func (m *Metadata) inspectCall(call *ast.CallExpr, d comments.Comments) {
 	case "flag.String":
 		checkNotSet(d.Default, "flag.String", "default")
 		checkNotSet(d.Desc, "flag.String", "description")
-		m.AddInput(
+		m.addInput(call,
 			unqoute(stringValue(call.Args[0])),
 			Input{
 				Default:  omitEmpty(unqoute(stringValue(call.Args[1]))),
 				Desc:     stringValue(call.Args[2]),
 				Required: d.Required.Value,
 				tp:       inputFlag,
+				secret:   d.Secret.Value,
 			})
 	case "flag.StringVar":
 		checkNotSet(d.Default, "flag.StringVar", "default")
 		checkNotSet(d.Desc, "flag.StringVar", "description")
-		m.AddInput(
+		m.addInput(call,
 			unqoute(stringValue(call.Args[1])),
 			Input{
 				Default:  omitEmpty(unqoute(stringValue(call.Args[2]))),
 				Desc:     stringValue(call.Args[3]),
 				Required: d.Required.Value,
 				tp:       inputFlag,
+				secret:   d.Secret.Value,
 			})
 	case "flag.Int":
 		checkNotSet(d.Default, "flag.Int", "default")
 		checkNotSet(d.Desc, "flag.Int", "description")
-		m.AddInput(
+		m.addInput(call,
 			unqoute(stringValue(call.Args[0])),
 			Input{
 				Default:  intValue(call.Args[1]),
 				Desc:     stringValue(call.Args[2]),
 				Required: d.Required.Value,
 				tp:       inputFlag,
+				secret:   d.Secret.Value,
 			})
 	case "flag.IntVar":
 		checkNotSet(d.Default, "flag.IntVar", "default")
 		checkNotSet(d.Desc, "flag.IntVar", "description")
-		m.AddInput(
+		m.addInput(call,
 			unqoute(stringValue(call.Args[1])),
 			Input{
 				Default:  intValue(call.Args[2]),
 				Desc:     stringValue(call.Args[3]),
 				Required: d.Required.Value,
 				tp:       inputFlag,
+				secret:   d.Secret.Value,
 			})
 	case "flag.Bool":
 		checkNotSet(d.Default, "flag.Bool", "default")
 		checkNotSet(d.Desc, "flag.Bool", "description")
-		m.AddInput(
+		m.addInput(call,
 			unqoute(stringValue(call.Args[0])),
 			Input{
 				Default:  boolValue(call.Args[1]),
 				Desc:     stringValue(call.Args[2]),
 				Required: d.Required.Value,
 				tp:       inputFlag,
+				secret:   d.Secret.Value,
 			})
 	case "flag.BoolVar":
 		checkNotSet(d.Default, "flag.BoolVar", "default")
 		checkNotSet(d.Desc, "flag.BoolVar", "description")
-		m.AddInput(
+		m.addInput(call,
 			unqoute(stringValue(call.Args[1])),
 			Input{
 				Default:  boolValue(call.Args[2]),
 				Desc:     stringValue(call.Args[3]),
 				Required: d.Required.Value,
 				tp:       inputFlag,
+				secret:   d.Secret.Value,
 			})
 	case "os.Getenv":
-		m.AddInput(
+		m.addInput(call,
 			unqoute(stringValue(call.Args[0])),
 			Input{
 				Default:  omitEmpty(d.Default.Value),
 				Desc:     d.Desc.Value,
 				Required: d.Required.Value,
 				tp:       inputEnv,
+				secret:   d.Secret.Value,
+			})
+	case "goaction.Input", "goaction.InputBool", "goaction.InputInt", "goaction.InputDuration", "goaction.InputList":
+		m.addInput(call,
+			unqoute(stringValue(call.Args[0])),
+			Input{
+				Default:  omitEmpty(d.Default.Value),
+				Desc:     d.Desc.Value,
+				Required: d.Required.Value,
+				tp:       inputAction,
+				secret:   d.Secret.Value,
+			})
+	case "goaction.InputRequired":
+		checkNotSet(d.Default, fullName, "default")
+		m.addInput(call,
+			unqoute(stringValue(call.Args[0])),
+			Input{
+				Desc:     d.Desc.Value,
+				Required: true,
+				tp:       inputAction,
+				secret:   d.Secret.Value,
 			})
-	case "goaction.Output":
-		checkNotSet(d.Default, "goaction.Output", "default")
-		checkNotSet(d.Desc, "goaction.Output", "description")
+	case "goaction.Output", "goaction.OutputJSON":
+		checkNotSet(d.Default, fullName, "default")
+		checkNotSet(d.Desc, fullName, "description")
 		m.AddOutput(
 			unqoute(stringValue(call.Args[0])),
 			Output{
@@ -282,7 +411,29 @@ func calcEnv(inputs yaml.MapSlice /* map[string]Input */) (yaml.MapSlice /* map[
 		if input.tp != inputEnv {
 			continue
 		}
-		envs = append(envs, yaml.MapItem{name, fmt.Sprintf("\"${{ inputs.%s }}\"", name)})
+		envs = append(envs, yaml.MapItem{Key: name, Value: fmt.Sprintf("\"${{ inputs.%s }}\"", name)})
+	}
+	// Pass the names of the secret inputs, such that the goaction package will mask them when it is
+	// initialized.
+	var secrets []string
+	for _, mapItem := range inputs {
+		if mapItem.Value.(Input).secret {
+			secrets = append(secrets, mapItem.Key.(string))
+		}
+	}
+	if len(secrets) > 0 {
+		envs = append(envs, yaml.MapItem{Key: SecretsEnv, Value: strconv.Quote(strings.Join(secrets, ","))})
+	}
+	// Pass the names of the required inputs, such that the goaction Input functions can tell that
+	// an empty input was not supplied.
+	var required []string
+	for _, mapItem := range inputs {
+		if in := mapItem.Value.(Input); in.Required && in.tp == inputAction {
+			required = append(required, mapItem.Key.(string))
+		}
+	}
+	if len(required) > 0 {
+		envs = append(envs, yaml.MapItem{Key: RequiredEnv, Value: strconv.Quote(strings.Join(required, ","))})
 	}
 	return envs, nil
 }
@@ -333,6 +484,14 @@ func unqoute(s string) string {
 	return uq
 }
 
+// secretDesc marks a quoted description as a secret input description.
+func secretDesc(desc string) string {
+	if desc == "" {
+		return strconv.Quote("(Secret)")
+	}
+	return strconv.Quote("(Secret) " + unqoute(desc))
+}
+
 func omitEmpty(s string) interface{} {
 	if s == "" {
 		return nil
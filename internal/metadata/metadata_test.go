@@ -1,6 +1,7 @@
 package metadata
 
 import (
+	"errors"
 	"go/ast"
 	"go/parser"
 	"go/token"
@@ -34,6 +35,13 @@ var (
 
 	_ = os.Getenv("env")
 
+	_ = goaction.Input("input")
+	_, _ = goaction.InputRequired("input-required")
+	_, _ = goaction.InputBool("input-bool")
+	_, _ = goaction.InputInt("input-int")
+	_, _ = goaction.InputDuration("input-duration")
+	_ = goaction.InputList("input-list")
+
 	s string
 	i int
 	b bool
@@ -49,6 +57,7 @@ func init() {
 
 func main() {
 	goaction.Output("out", "value", "output description")
+	goaction.OutputJSON("out-json", []string{"value"}, "output json description")
 }
 `
 
@@ -56,20 +65,27 @@ func main() {
 		Name: "main",
 		Desc: "\"Package main tests parsing of input calls.\"",
 		Inputs: yaml.MapSlice{
-			{"string", Input{tp: inputFlag, Desc: "\"string usage\""}},
-			{"string-default", Input{tp: inputFlag, Default: "default", Desc: "\"string default usage\""}},
-			{"int", Input{tp: inputFlag, Default: 1, Desc: "\"int usage\""}},
-			{"bool-true", Input{tp: inputFlag, Default: true, Desc: "\"bool true usage\""}},
-			{"bool-false", Input{tp: inputFlag, Default: false, Desc: "\"bool false usage\""}},
-			{"env", Input{tp: inputEnv}},
-			{"string-var", Input{tp: inputFlag, Desc: "\"string var usage\""}},
-			{"string-var-default", Input{tp: inputFlag, Default: "default", Desc: "\"string var default usage\""}},
-			{"int-var", Input{tp: inputFlag, Default: 0, Desc: "\"int var usage\""}},
-			{"bool-var-true", Input{tp: inputFlag, Default: true, Desc: "\"bool var true usage\""}},
-			{"bool-var-false", Input{tp: inputFlag, Default: false, Desc: "\"bool var false usage\""}},
+			{Key: "string", Value: Input{tp: inputFlag, Desc: "\"string usage\""}},
+			{Key: "string-default", Value: Input{tp: inputFlag, Default: "default", Desc: "\"string default usage\""}},
+			{Key: "int", Value: Input{tp: inputFlag, Default: 1, Desc: "\"int usage\""}},
+			{Key: "bool-true", Value: Input{tp: inputFlag, Default: true, Desc: "\"bool true usage\""}},
+			{Key: "bool-false", Value: Input{tp: inputFlag, Default: false, Desc: "\"bool false usage\""}},
+			{Key: "env", Value: Input{tp: inputEnv}},
+			{Key: "input", Value: Input{tp: inputAction}},
+			{Key: "input-required", Value: Input{tp: inputAction, Required: true}},
+			{Key: "input-bool", Value: Input{tp: inputAction}},
+			{Key: "input-int", Value: Input{tp: inputAction}},
+			{Key: "input-duration", Value: Input{tp: inputAction}},
+			{Key: "input-list", Value: Input{tp: inputAction}},
+			{Key: "string-var", Value: Input{tp: inputFlag, Desc: "\"string var usage\""}},
+			{Key: "string-var-default", Value: Input{tp: inputFlag, Default: "default", Desc: "\"string var default usage\""}},
+			{Key: "int-var", Value: Input{tp: inputFlag, Default: 0, Desc: "\"int var usage\""}},
+			{Key: "bool-var-true", Value: Input{tp: inputFlag, Default: true, Desc: "\"bool var true usage\""}},
+			{Key: "bool-var-false", Value: Input{tp: inputFlag, Default: false, Desc: "\"bool var false usage\""}},
 		},
 		Outputs: yaml.MapSlice{
-			{"out", Output{Desc: "\"output description\""}},
+			{Key: "out", Value: Output{Desc: "\"output description\""}},
+			{Key: "out-json", Value: Output{Desc: "\"output json description\""}},
 		},
 		Runs: Runs{
 			Using: "docker",
@@ -87,7 +103,8 @@ func main() {
 				"\"-bool-var-false=${{ inputs.bool-var-false }}\"",
 			},
 			Env: yaml.MapSlice{
-				{"env", "\"${{ inputs.env }}\""},
+				{Key: "env", Value: "\"${{ inputs.env }}\""},
+				{Key: RequiredEnv, Value: "\"input-required\""},
 			},
 		},
 	}
@@ -138,18 +155,29 @@ var (
 	// Test environment variable required and description.
 	//goaction:required
 	_ = os.Getenv("env")
+
+	// Test goaction input required.
+	//goaction:required
+	_, _ = goaction.InputBool("input")
+	_, _ = goaction.InputRequired("input-required")
 )
 `
 
 	var wantInputs = yaml.MapSlice{
-		{"simple1", Input{tp: inputFlag, Desc: "\"simple1\"", Required: true}},
-		{"simple2", Input{tp: inputFlag, Desc: "\"simple2\""}},
-		{"multi1", Input{tp: inputFlag, Desc: "\"multi1\"", Required: true}},
-		{"multi2", Input{tp: inputFlag, Desc: "\"multi2\"", Required: true}},
-		{"var", Input{tp: inputFlag, Desc: "\"var\"", Required: true}},
-		{"block1", Input{tp: inputFlag, Desc: "\"block1\"", Required: true}},
-		{"block2", Input{tp: inputFlag, Desc: "\"block2\"", Required: true}},
-		{"env", Input{tp: inputEnv, Required: true}},
+		{Key: "simple1", Value: Input{tp: inputFlag, Desc: "\"simple1\"", Required: true}},
+		{Key: "simple2", Value: Input{tp: inputFlag, Desc: "\"simple2\""}},
+		{Key: "multi1", Value: Input{tp: inputFlag, Desc: "\"multi1\"", Required: true}},
+		{Key: "multi2", Value: Input{tp: inputFlag, Desc: "\"multi2\"", Required: true}},
+		{Key: "var", Value: Input{tp: inputFlag, Desc: "\"var\"", Required: true}},
+		{Key: "block1", Value: Input{tp: inputFlag, Desc: "\"block1\"", Required: true}},
+		{Key: "block2", Value: Input{tp: inputFlag, Desc: "\"block2\"", Required: true}},
+		{Key: "env", Value: Input{tp: inputEnv, Required: true}},
+		{Key: "input", Value: Input{tp: inputAction, Required: true}},
+		{Key: "input-required", Value: Input{tp: inputAction, Required: true}},
+	}
+	var wantEnv = yaml.MapSlice{
+		{Key: "env", Value: "\"${{ inputs.env }}\""},
+		{Key: RequiredEnv, Value: "\"input,input-required\""},
 	}
 
 	got, err := parse(code)
@@ -157,6 +185,7 @@ var (
 		t.Fatal(err)
 	}
 	assert.Equal(t, wantInputs, got.Inputs)
+	assert.Equal(t, wantEnv, got.Runs.Env)
 }
 
 func TestNewDefaultDesc(t *testing.T) {
@@ -175,10 +204,16 @@ import (
 //goaction:default default
 //goaction:description input from environment variable
 var	_ = os.Getenv("env")
+
+// Test action input default and description.
+//goaction:default default
+//goaction:description action input
+var	_ = goaction.Input("input")
 `
 
 	var wantInputs = yaml.MapSlice{
-		{"env", Input{tp: inputEnv, Default: "default", Desc: "\"input from environment variable\""}},
+		{Key: "env", Value: Input{tp: inputEnv, Default: "default", Desc: "\"input from environment variable\""}},
+		{Key: "input", Value: Input{tp: inputAction, Default: "default", Desc: "\"action input\""}},
 	}
 
 	got, err := parse(code)
@@ -230,7 +265,7 @@ var (
 `
 
 	var wantInputs = yaml.MapSlice{
-		{"simple2", Input{tp: inputFlag, Desc: "\"simple2\""}},
+		{Key: "simple2", Value: Input{tp: inputFlag, Desc: "\"simple2\""}},
 	}
 
 	got, err := parse(code)
@@ -268,21 +303,92 @@ var _ = flag.String("simple1", "", "simple1")
 	}
 }
 
+func TestNewRepeatedInput(t *testing.T) {
+	t.Parallel()
+
+	code := `
+package main
+
+import "github.com/posener/goaction"
+
+var (
+	//goaction:description mode of the action
+	_ = goaction.Input("mode")
+	_ = goaction.Input("mode")
+	//goaction:default fast
+	_ = goaction.Input("mode")
+)
+
+func main() {
+	_ = goaction.Input("mode")
+}
+`
+
+	got, err := parse(code)
+	require.NoError(t, err)
+	assert.Equal(t, yaml.MapSlice{
+		{Key: "mode", Value: Input{tp: inputAction, Default: "fast", Desc: `"mode of the action"`}},
+	}, got.Inputs)
+}
+
+func TestNewRepeatedInputConflict(t *testing.T) {
+	t.Parallel()
+
+	codes := []string{
+		`
+package main
+import ("os"; "github.com/posener/goaction")
+var _ = goaction.Input("mode")
+var _ = os.Getenv("mode")
+`,
+		`
+package main
+import "github.com/posener/goaction"
+var _ = goaction.Input("mode")
+var _, _ = goaction.InputRequired("mode")
+`,
+		`
+package main
+import "github.com/posener/goaction"
+//goaction:default fast
+var _ = goaction.Input("mode")
+//goaction:default slow
+var _ = goaction.Input("mode")
+`,
+		`
+package main
+import "github.com/posener/goaction"
+//goaction:description mode
+var _ = goaction.Input("mode")
+//goaction:description other mode
+var _ = goaction.Input("mode")
+`,
+	}
+
+	for _, code := range codes {
+		t.Run(code, func(t *testing.T) {
+			_, err := parse(strings.TrimSpace(code))
+			var pe ErrParse
+			assert.True(t, errors.As(err, &pe), "got %v", err)
+		})
+	}
+}
+
 func TestMarshal(t *testing.T) {
 	m := Metadata{
 		Name: "name",
 		Desc: "description",
 		Inputs: yaml.MapSlice{
-			{"in2", Input{tp: "tp2", Default: 1, Desc: "description 2"}},
-			{"in1", Input{tp: "tp1", Default: "string", Desc: "description 1"}},
+			{Key: "in2", Value: Input{tp: "tp2", Default: 1, Desc: "description 2"}},
+			{Key: "in1", Value: Input{tp: "tp1", Default: "string", Desc: "description 1"}},
 		},
 		Runs: Runs{
 			Using: "using",
 			Image: "image",
 			Args:  []string{"arg1", "arg2"},
 			Env: yaml.MapSlice{
-				{"key2", "value2"},
-				{"key1", "value1"},
+				{Key: "key2", Value: "value2"},
+				{Key: "key1", Value: "value1"},
 			},
 		},
 	}
@@ -331,3 +437,69 @@ func parse(code string) (Metadata, error) {
 	}
 	return New(pkg)
 }
+
+func TestNewSecret(t *testing.T) {
+	t.Parallel()
+
+	code := `
+package main
+
+import (
+	"flag"
+	"os"
+	"github.com/posener/goaction"
+)
+
+var (
+	//goaction:secret
+	_ = flag.String("token", "default-token", "token usage")
+
+	//goaction:secret
+	//goaction:description environment token
+	//goaction:default default-token
+	_ = os.Getenv("ENV_TOKEN")
+
+	//goaction:secret
+	_ = goaction.Input("input-token")
+
+	_ = os.Getenv("env")
+)
+`
+
+	var wantInputs = yaml.MapSlice{
+		{Key: "token", Value: Input{tp: inputFlag, secret: true, Desc: "\"(Secret) token usage\""}},
+		{Key: "ENV_TOKEN", Value: Input{tp: inputEnv, secret: true, Desc: "\"(Secret) environment token\""}},
+		{Key: "input-token", Value: Input{tp: inputAction, secret: true, Desc: "\"(Secret)\""}},
+		{Key: "env", Value: Input{tp: inputEnv}},
+	}
+	var wantEnv = yaml.MapSlice{
+		{Key: "ENV_TOKEN", Value: "\"${{ inputs.ENV_TOKEN }}\""},
+		{Key: "env", Value: "\"${{ inputs.env }}\""},
+		{Key: SecretsEnv, Value: "\"token,ENV_TOKEN,input-token\""},
+	}
+
+	got, err := parse(code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, wantInputs, got.Inputs)
+	assert.Equal(t, wantEnv, got.Runs.Env)
+}
+
+func TestNewSecretWithoutGoaction(t *testing.T) {
+	t.Parallel()
+
+	code := `
+package main
+
+import "os"
+
+var (
+	//goaction:secret
+	_ = os.Getenv("TOKEN")
+)
+`
+
+	_, err := parse(code)
+	assert.EqualError(t, err, "secret inputs are masked by the github.com/posener/goaction package, which is not imported by the action")
+}
@@ -0,0 +1,7 @@
+// Package testhook exposes state of the goaction packages that is kept for the whole process, such
+// that the goactiontest package can reset it between tests.
+package testhook
+
+// ResetLog resets the annotations budget, the error count and the fail on errors mode of the log
+// package. It is set by the log package.
+var ResetLog = func() {}
@@ -0,0 +1,126 @@
+package log
+
+import (
+	"fmt"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/posener/goaction"
+	"github.com/posener/goaction/internal/command"
+)
+
+// Annotation holds the properties of an annotation that is created by a log message. Annotations
+// with a file location are shown in the "Files changed" view of pull requests.
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#setting-an-error-message.
+//
+//	log.ErrorfAt(log.Annotation{
+//		Title: "Unused function",
+//		Start: token.Position{Filename: "main.go", Line: 10, Column: 1},
+//		End:   token.Position{Line: 12, Column: 2},
+//	}, "function %s is unused", name)
+type Annotation struct {
+	// Title of the annotation.
+	Title string
+	// Start is the file location of the annotation.
+	Start token.Position
+	// End is the optional end of the annotated range in the file of the start position. Only the
+	// line and the column are used.
+	End token.Position
+}
+
+// The directory that annotation file paths are relative to. If empty, the Github workspace is used.
+var root string
+
+// SetRoot sets the directory that file paths in annotations are made relative to. Github attaches
+// annotations to files only when their paths are relative to the repository root, which is by
+// default the Github workspace directory. It should be set when the repository is checked out to
+// a different directory.
+func SetRoot(dir string) {
+	root = dir
+}
+
+// relPath returns the path of a file relative to the root directory. Relative paths are relative
+// to the working directory. If the path is not in the root directory, it is returned as is.
+func relPath(path string) string {
+	dir := root
+	if dir == "" {
+		dir = goaction.Workspace
+	}
+	if dir == "" {
+		return path
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(dir, abs)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// properties returns the workflow command properties of the annotation. The file path is made
+// relative to the root directory.
+func (a Annotation) properties() []command.Property {
+	var props []command.Property
+	if a.Start.Filename != "" {
+		props = append(props, command.Property{Key: "file", Value: relPath(a.Start.Filename)})
+		if a.Start.Line > 0 {
+			props = append(props, command.Property{Key: "line", Value: strconv.Itoa(a.Start.Line)})
+			if a.Start.Column > 0 {
+				props = append(props, command.Property{Key: "col", Value: strconv.Itoa(a.Start.Column)})
+			}
+			if a.End.Line > 0 {
+				props = append(props, command.Property{Key: "endLine", Value: strconv.Itoa(a.End.Line)})
+				if a.End.Column > 0 {
+					props = append(props, command.Property{Key: "endColumn", Value: strconv.Itoa(a.End.Column)})
+				}
+			}
+		}
+	}
+	if a.Title != "" {
+		props = append(props, command.Property{Key: "title", Value: a.Title})
+	}
+	return props
+}
+
+// plain returns a message with the annotation title and file location, for plain log lines.
+func (a Annotation) plain(msg string) string {
+	if a.Title != "" {
+		msg = a.Title + ": " + msg
+	}
+	pos := a.posString()
+	if len(pos) > 0 {
+		pos = pos + ": "
+	}
+	return pos + msg
+}
+
+// posString returns the file location of the annotation, for plain log lines.
+func (a Annotation) posString() string {
+	p := a.Start
+	if p.Filename == "" {
+		return ""
+	}
+	pos := p.Filename
+	if p.Line > 0 {
+		pos += fmt.Sprintf("+%d", p.Line)
+		if p.Column > 0 {
+			pos += fmt.Sprintf(":%d", p.Column)
+		}
+		if a.End.Line > 0 {
+			pos += fmt.Sprintf("-%d", a.End.Line)
+			if a.End.Column > 0 {
+				pos += fmt.Sprintf(":%d", a.End.Column)
+			}
+		}
+	}
+	return pos
+}
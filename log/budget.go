@@ -0,0 +1,126 @@
+package log
+
+import (
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/posener/goaction"
+	"github.com/posener/goaction/internal/testhook"
+)
+
+// Github shows at most this number of annotations of each level in a step, and ignores the rest.
+const maxAnnotations = 10
+
+// Budget of the annotations of the process.
+var budget annotations
+
+func init() {
+	testhook.ResetLog = func() {
+		budget.reset()
+		resetErrors()
+	}
+}
+
+// annotations tracks the annotations that were logged in CI mode. Github limits the annotations of
+// a step, so they are tracked for the whole process.
+type annotations struct {
+	mu      sync.Mutex
+	counts  map[level]int
+	records []record
+	// Whether an annotation exceeded the budget of its level.
+	overflow bool
+	// Number of records that were written by Flush.
+	flushed int
+}
+
+type record struct {
+	level level
+	a     Annotation
+	msg   string
+}
+
+// add records an annotation. It returns false if the annotation exceeds the budget of its level.
+func (b *annotations) add(l level, a Annotation, msg string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.counts == nil {
+		b.counts = map[level]int{}
+	}
+	b.counts[l]++
+	b.records = append(b.records, record{level: l, a: a, msg: msg})
+	if b.counts[l] > maxAnnotations {
+		b.overflow = true
+		return false
+	}
+	return true
+}
+
+// unflushed returns the recorded annotations that were not flushed yet, if any annotation exceeded
+// the budget, and marks them as flushed. It also returns the number of annotations that were
+// flushed before.
+func (b *annotations) unflushed() (records []record, flushed int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if !b.overflow {
+		return nil, 0
+	}
+	records, flushed = b.records[b.flushed:], b.flushed
+	b.flushed = len(b.records)
+	return records, flushed
+}
+
+// reset clears the recorded annotations.
+func (b *annotations) reset() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.counts, b.records, b.overflow, b.flushed = nil, nil, false, 0
+}
+
+// Flush writes a table of all the annotations to the job summary, grouped by level, if some of the
+// annotations were not shown because Github limits the number of annotations in a step. Such
+// annotations are logged as plain lines. Flush is called by the Fatal functions and by Exit, and
+// otherwise should be called before the program exits. Flush can be called multiple times, and
+// each call writes only the annotations that were not written yet.
+func Flush() error {
+	records, flushed := budget.unflushed()
+	if len(records) == 0 {
+		return nil
+	}
+	s := goaction.NewSummary()
+	s.Heading(2, "Annotations")
+	if flushed == 0 {
+		s.Paragraph(fmt.Sprintf("Github shows only %d annotations of each level, all %d annotations are listed below.", maxAnnotations, len(records)))
+	} else {
+		s.Paragraph(fmt.Sprintf("%d more annotations were logged after the %d annotations that are listed above.", len(records), flushed))
+	}
+	for _, group := range []struct {
+		level level
+		title string
+	}{
+		{level: levelError, title: "Errors"},
+		{level: levelWarn, title: "Warnings"},
+		{level: levelNotice, title: "Notices"},
+	} {
+		var rows [][]string
+		for _, r := range records {
+			if r.level != group.level {
+				continue
+			}
+			file, line := "", ""
+			if r.a.Start.Filename != "" {
+				file = relPath(r.a.Start.Filename)
+			}
+			if r.a.Start.Line > 0 {
+				line = strconv.Itoa(r.a.Start.Line)
+			}
+			rows = append(rows, []string{file, line, r.a.Title, r.msg})
+		}
+		if len(rows) == 0 {
+			continue
+		}
+		s.Heading(3, fmt.Sprintf("%s (%d)", group.title, len(rows)))
+		s.Table([]string{"File", "Line", "Title", "Message"}, rows...)
+	}
+	return s.Write()
+}
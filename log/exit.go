@@ -0,0 +1,81 @@
+package log
+
+import (
+	"os"
+	"sync/atomic"
+)
+
+var (
+	// Number of error level messages that were logged.
+	errorCount int64
+	// Whether Exit fails the program if errors were logged.
+	failOnErrors int32
+	// Exits the program, replaced in tests.
+	exit = os.Exit
+)
+
+// resetErrors clears the error count and the fail on errors mode.
+func resetErrors() {
+	atomic.StoreInt64(&errorCount, 0)
+	FailOnErrors(false)
+}
+
+// ErrorCount returns the number of error level messages that were logged.
+func ErrorCount() int {
+	return int(atomic.LoadInt64(&errorCount))
+}
+
+// ExitIfErrors flushes the logs and fails the program if error level messages were logged.
+func ExitIfErrors() {
+	if ErrorCount() == 0 {
+		return
+	}
+	flush()
+	exit(1)
+}
+
+// FailOnErrors sets whether Exit fails the program if error level messages were logged. It allows
+// an action to report all the errors that it finds, and fail at the end:
+//
+//	func main() {
+//		log.FailOnErrors(true)
+//		defer log.Exit()
+//
+//		for _, f := range files {
+//			if err := check(f); err != nil {
+//				log.Errorf("%s: %s", f, err)
+//			}
+//		}
+//	}
+func FailOnErrors(fail bool) {
+	v := int32(0)
+	if fail {
+		v = 1
+	}
+	atomic.StoreInt32(&failOnErrors, v)
+}
+
+// Exit should be deferred in the main function. It flushes the logs, and if FailOnErrors was set
+// and error level messages were logged, it fails the program.
+//
+// Exit must be deferred directly, as in `defer log.Exit()`, since it recovers a panic of the main
+// function: it flushes the logs and panics again with the same value. The stack trace of the panic
+// still shows where the original panic happened.
+func Exit() {
+	if r := recover(); r != nil {
+		flush()
+		panic(r)
+	}
+	flush()
+	if atomic.LoadInt32(&failOnErrors) == 1 && ErrorCount() > 0 {
+		exit(1)
+	}
+}
+
+// flush flushes the logs before the program exits, and logs the flush error.
+func flush() {
+	err := Flush()
+	if err != nil {
+		Warnf("Failed flushing logs: %s", err)
+	}
+}
@@ -0,0 +1,83 @@
+package log
+
+import (
+	"bytes"
+	"go/token"
+	"testing"
+
+	"github.com/posener/goaction"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExit(t *testing.T) {
+	oldCI, oldOut, oldExit, oldCount := goaction.CI, std.Writer(), exit, errorCount
+	defer func() {
+		goaction.CI, exit, errorCount = oldCI, oldExit, oldCount
+		std.SetOutput(oldOut)
+		FailOnErrors(false)
+	}()
+	goaction.CI = false
+	std.SetOutput(&bytes.Buffer{})
+	errorCount = 0
+
+	code := -1
+	exit = func(c int) { code = c }
+
+	// No errors.
+	ExitIfErrors()
+	Exit()
+	assert.Equal(t, -1, code)
+
+	Warnf("warning")
+	Errorf("error")
+	ErrorfFile(token.Position{Filename: "foo.go"}, "error")
+	assert.Equal(t, 2, ErrorCount())
+
+	// Errors without fail on errors mode.
+	Exit()
+	assert.Equal(t, -1, code)
+
+	FailOnErrors(true)
+	Exit()
+	assert.Equal(t, 1, code)
+
+	code = -1
+	ExitIfErrors()
+	assert.Equal(t, 1, code)
+}
+
+func TestFatal(t *testing.T) {
+	oldExit, oldCount := exit, errorCount
+	defer func() { exit, errorCount = oldExit, oldCount }()
+
+	code := -1
+	exit = func(c int) { code = c }
+
+	var b bytes.Buffer
+	l := New(&b, Options{Mode: ModeLocal})
+	p := token.Position{Filename: "foo.go", Line: 3}
+	l.FatalfAt(Annotation{Title: "Title", Start: p}, "fatalf %d", 1)
+	assert.Equal(t, 1, code)
+	l.FatalAt(Annotation{Start: p}, "fatal ", 2)
+	l.FatalfFile(p, "fatalf %d", 3)
+	l.FatalFile(p, "fatal ", 4)
+
+	want := "foo.go+3: Title: fatalf 1\nfoo.go+3: fatal 2\nfoo.go+3: fatalf 3\nfoo.go+3: fatal 4\n"
+	assert.Equal(t, want, b.String())
+}
+
+func TestExitPanic(t *testing.T) {
+	oldExit, oldCount := exit, errorCount
+	defer func() {
+		exit, errorCount = oldExit, oldCount
+		FailOnErrors(false)
+	}()
+	exit = func(int) { t.Fatal("unexpected exit") }
+	errorCount = 1
+	FailOnErrors(true)
+
+	assert.PanicsWithValue(t, "panic", func() {
+		defer Exit()
+		panic("panic")
+	})
+}
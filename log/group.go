@@ -0,0 +1,102 @@
+package log
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Indentation of logs in local mode groups.
+const groupIndent = "  "
+
+type group struct {
+	title string
+	start time.Time
+}
+
+// groupStack holds the open groups, the last one is the innermost group. It can be used
+// concurrently.
+type groupStack struct {
+	mu     sync.Mutex
+	groups []group
+}
+
+// push opens a group and returns the number of open groups.
+func (s *groupStack) push(title string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups = append(s.groups, group{title: title, start: time.Now()})
+	return len(s.groups)
+}
+
+// pop closes the innermost group and returns it with the number of groups that are still open. It
+// returns false if there are no open groups.
+func (s *groupStack) pop() (group, int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.groups) == 0 {
+		return group{}, 0, false
+	}
+	g := s.groups[len(s.groups)-1]
+	s.groups = s.groups[:len(s.groups)-1]
+	return g, len(s.groups), true
+}
+
+// Group starts a collapsible group of log lines, which ends with EndGroup. In CI mode, the group is
+// folded in the workflow run log. In local mode, the group title is printed as a section header
+// and the logs of the group are indented.
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#grouping-log-lines.
+func Group(title string) {
+	std.Group(title)
+}
+
+// EndGroup ends the group that was started by the last call to Group. In local mode, the time that
+// the group took is printed.
+func EndGroup() {
+	std.EndGroup()
+}
+
+// InGroup runs a function within a group of log lines, and returns its error.
+func InGroup(title string, f func() error) error {
+	return std.InGroup(title, f)
+}
+
+// Group starts a collapsible group of log lines of the logger, which ends with EndGroup.
+func (l *Logger) Group(title string) {
+	if l.ci() {
+		l.command("group", title)
+	} else {
+		l.l.Print("▸ " + title)
+	}
+	l.setGroupPrefix(l.groups.push(title))
+}
+
+// EndGroup ends the group that was started by the last call to Group of the logger.
+func (l *Logger) EndGroup() {
+	g, n, ok := l.groups.pop()
+	if !ok {
+		return
+	}
+	l.setGroupPrefix(n)
+	if l.ci() {
+		l.command("endgroup", "")
+	} else {
+		l.l.Printf("◂ %s (%s)", g.title, time.Since(g.start).Round(time.Millisecond))
+	}
+}
+
+// InGroup runs a function within a group of log lines of the logger, and returns its error.
+func (l *Logger) InGroup(title string, f func() error) error {
+	l.Group(title)
+	defer l.EndGroup()
+	return f()
+}
+
+// setGroupPrefix indents the logs of n open groups in local mode. Github does not support nested
+// groups, so the logs are not indented in CI mode.
+func (l *Logger) setGroupPrefix(n int) {
+	if l.ci() {
+		return
+	}
+	l.l.SetPrefix(strings.Repeat(groupIndent, n))
+}
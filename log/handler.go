@@ -0,0 +1,182 @@
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"context"
+	"go/token"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/posener/goaction"
+)
+
+// NewHandler returns a slog.Handler that logs with the log package. Debug, info, warning and
+// error records are logged as debug messages, plain messages, warnings and errors respectively.
+// Debug records are logged only when debug logging is enabled for the run (see
+// goaction.RunnerDebug).
+//
+// In debug, warning and error records, which are logged as annotations, the "file" and "line"
+// attributes, or a slog.Source attribute, set the file location of the annotation. Other attributes,
+// and all the attributes of info records, are appended to the message as key=value pairs.
+//
+//	logger := slog.New(log.NewHandler(log.HandlerOptions{}))
+//	logger.Error("unused variable", "file", "main.go", "line", 10, "name", "x")
+func NewHandler(opts HandlerOptions) slog.Handler {
+	return std.Handler(opts)
+}
+
+// HandlerOptions are options for a slog.Handler of the log package.
+type HandlerOptions struct {
+	// AddSource sets the file location of annotations without file location attributes to the
+	// location of the call to the slog.Logger. It should be used only when the source code of the
+	// action is in the Github workspace, otherwise the annotations point at files that don't exist
+	// in the repository.
+	AddSource bool
+}
+
+// Handler returns a slog.Handler that logs with the logger. See NewHandler.
+func (l *Logger) Handler(opts HandlerOptions) slog.Handler {
+	return &handler{l: l, addSource: opts.AddSource}
+}
+
+type handler struct {
+	l         *Logger
+	addSource bool
+	// Attributes that were added with WithAttrs, with the group prefix already in their key.
+	attrs []slog.Attr
+	// Prefix for attribute keys, from the groups that were added with WithGroup.
+	prefix string
+}
+
+func (h *handler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= slog.LevelInfo || goaction.RunnerDebug
+}
+
+func (h *handler) Handle(_ context.Context, r slog.Record) error {
+	var (
+		pos   token.Position
+		attrs = append([]slog.Attr(nil), h.attrs...)
+	)
+	r.Attrs(func(a slog.Attr) bool {
+		attrs = append(attrs, slog.Attr{Key: h.prefix + a.Key, Value: a.Value})
+		return true
+	})
+
+	// Info records are logged as plain messages, so they don't have a file location.
+	annotate := r.Level < slog.LevelInfo || r.Level >= slog.LevelWarn
+
+	var b strings.Builder
+	b.WriteString(r.Message)
+	for _, a := range attrs {
+		if annotate && setPosition(&pos, a) {
+			continue
+		}
+		appendAttr(&b, "", a)
+	}
+	msg := b.String()
+	if annotate && h.addSource && pos.Filename == "" && pos.Line == 0 && r.PC != 0 {
+		pos = callerPosition(r.PC)
+	}
+
+	switch {
+	case r.Level < slog.LevelInfo:
+		h.l.DebugfAt(Annotation{Start: pos}, "%s", msg)
+	case r.Level < slog.LevelWarn:
+		h.l.Printf("%s", msg)
+	case r.Level < slog.LevelError:
+		h.l.WarnfAt(Annotation{Start: pos}, "%s", msg)
+	default:
+		h.l.ErrorfAt(Annotation{Start: pos}, "%s", msg)
+	}
+	return nil
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	h2 := *h
+	h2.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		h2.attrs = append(h2.attrs, slog.Attr{Key: h.prefix + a.Key, Value: a.Value})
+	}
+	return &h2
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix = h.prefix + name + "."
+	return &h2
+}
+
+// setPosition sets the file position from an attribute. It returns false if the attribute is not
+// a file position attribute.
+func setPosition(pos *token.Position, a slog.Attr) bool {
+	v := a.Value.Resolve()
+	if src, ok := v.Any().(*slog.Source); ok && src != nil {
+		pos.Filename, pos.Line = src.File, src.Line
+		return true
+	}
+	if src, ok := v.Any().(slog.Source); ok {
+		pos.Filename, pos.Line = src.File, src.Line
+		return true
+	}
+	switch a.Key {
+	case "file":
+		pos.Filename = v.String()
+		return true
+	case "line":
+		if v.Kind() == slog.KindInt64 {
+			pos.Line = int(v.Int64())
+			return true
+		}
+		if line, err := strconv.Atoi(v.String()); err == nil {
+			pos.Line = line
+			return true
+		}
+	}
+	return false
+}
+
+// callerPosition returns the file position of a program counter.
+func callerPosition(pc uintptr) token.Position {
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	return token.Position{Filename: frame.File, Line: frame.Line}
+}
+
+// appendAttr appends an attribute to the message as a key=value pair. Group attributes are
+// flattened with a dot separated key.
+func appendAttr(b *strings.Builder, prefix string, a slog.Attr) {
+	v := a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return
+	}
+	if v.Kind() == slog.KindGroup {
+		if a.Key != "" {
+			prefix += a.Key + "."
+		}
+		for _, ga := range v.Group() {
+			appendAttr(b, prefix, ga)
+		}
+		return
+	}
+	b.WriteString(" ")
+	b.WriteString(prefix + a.Key)
+	b.WriteString("=")
+	b.WriteString(quote(v.String()))
+}
+
+// quote quotes a value if it can't be read unambiguously in a key=value pair.
+func quote(s string) string {
+	if s == "" || strings.IndexFunc(s, func(r rune) bool {
+		return unicode.IsSpace(r) || r == '=' || r == '"' || !unicode.IsPrint(r)
+	}) >= 0 {
+		return strconv.Quote(s)
+	}
+	return s
+}
@@ -0,0 +1,77 @@
+//go:build go1.21
+// +build go1.21
+
+package log_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/posener/goaction"
+	"github.com/posener/goaction/goactiontest"
+	"github.com/posener/goaction/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	logger := slog.New(log.NewHandler(log.HandlerOptions{}))
+
+	logger.Debug("not logged")
+	goaction.RunnerDebug = true
+	logger.Debug("debug", "key", "value")
+	logger.Info("info", "quoted", "a b", "empty", "", "n", 1)
+	logger.Info("processing", "file", "main.go", "line", 5)
+	logger.Warn("warn", "file", "main.go", "line", 10)
+	logger.Error("error", "source", &slog.Source{File: "main.go", Line: 20})
+	logger.With("a", 1).WithGroup("g").With("b", 2).Info("group", "c", 3, slog.Group("h", "d", 4))
+	logger.Error("line string", "file", "main.go", "line", "30", "file2", "other.go")
+
+	want := `::debug::debug key=value
+info quoted="a b" empty="" n=1
+processing file=main.go line=5
+::warning file=main.go,line=10::warn
+::error file=main.go,line=20::error
+group a=1 g.b=2 g.c=3 g.h.d=4
+::error file=main.go,line=30::line string file2=other.go
+`
+	assert.Equal(t, want, env.Log())
+}
+
+func TestHandlerLocal(t *testing.T) {
+	env := goactiontest.New(t, goactiontest.WithEnv("CI", "false"))
+	defer env.Close()
+
+	logger := slog.New(log.NewHandler(log.HandlerOptions{}))
+	logger.Info("info", "key", "value")
+	logger.Error("error", "file", "main.go", "line", 10)
+
+	assert.Equal(t, "info key=value\nmain.go+10: error\n", env.Log())
+}
+
+func TestHandlerAddSource(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	logger := slog.New(log.NewHandler(log.HandlerOptions{AddSource: true}))
+	_, file, line, _ := runtime.Caller(0)
+	logger.Warn("warn")
+	logger.Info("info")
+	logger.Error("error", "file", "main.go", "line", 10)
+
+	want := fmt.Sprintf("::warning file=%s,line=%d::warn\ninfo\n::error file=main.go,line=10::error\n", file, line+1)
+	assert.Equal(t, want, env.Log())
+
+	// Records without a program counter are not annotated at a file location.
+	err := logger.Handler().Handle(context.Background(), slog.NewRecord(time.Now(), slog.LevelError, "error", 0))
+	require.NoError(t, err)
+	assert.True(t, strings.HasSuffix(env.Log(), "\n::error::error\n"))
+}
@@ -5,32 +5,33 @@
 // 	-	"log"
 // 	+	"github.com/posener/goaction/log"
 // 	 )
+//
+// The package level functions log with a default logger. Library code can accept a *Logger
+// instead, and tests can create one with New in order to capture its output.
+//
+// Code that logs with the "log/slog" package (Go 1.21 or later) can log with this package using
+// the handler that is returned by NewHandler.
+//
+// Problem matchers, which create annotations from the output of other tools, such as go vet, are
+// added with AddMatchers.
 package log
 
 import (
-	"fmt"
 	"go/token"
-	"log"
+	"io"
 	"os"
-	"strings"
 
 	"github.com/posener/goaction"
 )
 
-var logger *log.Logger
+// std is the default logger, which is used by the package level functions.
+var std *Logger
 
 const (
-	levelDebug level = "::debug%s::"
-	levelWarn  level = "::warning%s::"
-	levelError level = "::error%s::"
-)
-
-var (
-	// File position formattings:
-	formatFile string
-	formatLine string
-	formatCol  string
-	formatJoin string
+	levelDebug  level = "debug"
+	levelNotice level = "notice"
+	levelWarn   level = "warning"
+	levelError  level = "error"
 )
 
 func init() {
@@ -38,117 +39,133 @@ func init() {
 	if !goaction.CI {
 		out = os.Stderr
 	}
-	logger = log.New(out, "", 0)
-	initFormats()
-}
-
-// initFormats initializes format strings. Exists for testing purposes.
-func initFormats() {
-	if goaction.CI {
-		formatFile = "file=%s"
-		formatLine = "line=%d"
-		formatCol = "col=%d"
-		formatJoin = ","
-	} else {
-		formatFile = "%s"
-		formatLine = "+%d"
-		formatCol = ":%d"
-		formatJoin = ""
-	}
+	std = New(out, Options{})
 }
 
-type level string
+// Default returns the default logger, which is used by the package level functions.
+func Default() *Logger {
+	return std
+}
 
-func (l level) format(p token.Position) string {
-	pos := posString(p)
-	if !goaction.CI {
-		if len(pos) > 0 {
-			pos = pos + ": "
-		}
-		return pos
-	}
-	if len(pos) > 0 {
-		pos = " " + pos
-	}
-	return fmt.Sprintf(string(l), pos)
+// SetOutput sets the output destination of the logs. By default, logs are written to stdout in CI
+// mode and to stderr otherwise.
+func SetOutput(w io.Writer) {
+	std.SetOutput(w)
 }
 
+// Writer returns the output destination of the logs.
+func Writer() io.Writer {
+	return std.Writer()
+}
+
+type level string
+
 // Printf logs an info level message.
 func Printf(format string, args ...interface{}) {
-	logger.Printf(format, args...)
+	std.Printf(format, args...)
 }
 
 // Debugf logs a debug level message. To view these logs, set secret ACTIONS_STEP_DEBUG=true at
 // https://github.com/<repo>/settings/secrets/new.
 func Debugf(format string, args ...interface{}) {
-	DebugfFile(token.Position{}, format, args...)
+	std.Debugf(format, args...)
 }
 
 // DebugfFile logs a debug level message with a file location. To view these logs, set secret
 // variable ACTIONS_STEP_DEBUG=true at https://github.com/<repo>/settings/secrets/new.
 func DebugfFile(p token.Position, format string, args ...interface{}) {
-	logger.Printf(levelDebug.format(p)+format, args...)
+	std.DebugfFile(p, format, args...)
+}
+
+// DebugfAt logs a debug level message with annotation properties.
+func DebugfAt(a Annotation, format string, args ...interface{}) {
+	std.DebugfAt(a, format, args...)
+}
+
+// Noticef logs a notice level message.
+func Noticef(format string, args ...interface{}) {
+	std.Noticef(format, args...)
+}
+
+// NoticefFile logs a notice level message with a file location.
+func NoticefFile(p token.Position, format string, args ...interface{}) {
+	std.NoticefFile(p, format, args...)
+}
+
+// NoticefAt logs a notice level message with annotation properties.
+func NoticefAt(a Annotation, format string, args ...interface{}) {
+	std.NoticefAt(a, format, args...)
 }
 
 // Warnf logs a warning level message.
 func Warnf(format string, args ...interface{}) {
-	WarnfFile(token.Position{}, format, args...)
+	std.Warnf(format, args...)
 }
 
 // WarnfFile logs a warning level message with a file location.
 func WarnfFile(p token.Position, format string, args ...interface{}) {
-	logger.Printf(levelWarn.format(p)+format, args...)
+	std.WarnfFile(p, format, args...)
+}
+
+// WarnfAt logs a warning level message with annotation properties.
+func WarnfAt(a Annotation, format string, args ...interface{}) {
+	std.WarnfAt(a, format, args...)
 }
 
 // Errorf logs an error level message.
 func Errorf(format string, args ...interface{}) {
-	ErrorfFile(token.Position{}, format, args...)
+	std.Errorf(format, args...)
 }
 
 // ErrorfFile logs an error level message with a file location.
 func ErrorfFile(p token.Position, format string, args ...interface{}) {
-	logger.Printf(levelError.format(p)+format, args...)
+	std.ErrorfFile(p, format, args...)
+}
+
+// ErrorfAt logs an error level message with annotation properties.
+func ErrorfAt(a Annotation, format string, args ...interface{}) {
+	std.ErrorfAt(a, format, args...)
 }
 
 // Fatalf logs an error level message, and fails the program.
 func Fatalf(format string, args ...interface{}) {
-	FatalfFile(token.Position{}, format, args...)
+	std.Fatalf(format, args...)
 }
 
-// FatalfFile logs an error level message with a file location, and fails the program.
+// FatalfFile logs an error level message with a file location, and fails the program. It is a
+// shorthand for FatalfAt with an annotation that starts at the location.
 func FatalfFile(p token.Position, format string, args ...interface{}) {
-	logger.Fatalf(levelError.format(p)+format, args...)
+	std.FatalfFile(p, format, args...)
+}
+
+// FatalfAt logs an error level message with annotation properties, and fails the program.
+func FatalfAt(a Annotation, format string, args ...interface{}) {
+	std.FatalfAt(a, format, args...)
 }
 
 // Fatal logs an error level message, and fails the program.
 func Fatal(v ...interface{}) {
-	FatalFile(token.Position{}, v...)
+	std.Fatal(v...)
 }
 
-// FatalFile logs an error level message with a file location, and fails the program.
+// FatalFile logs an error level message with a file location, and fails the program. It is a
+// shorthand for FatalAt with an annotation that starts at the location.
 func FatalFile(p token.Position, v ...interface{}) {
-	logger.Fatal(append([]interface{}{levelError.format(p)}, v...)...)
+	std.FatalFile(p, v...)
 }
 
-func posString(p token.Position) string {
-	if p.Filename == "" {
-		return ""
-	}
-
-	parts := []string{fmt.Sprintf(formatFile, p.Filename)}
-	if p.Line > 0 {
-		parts = append(parts, fmt.Sprintf(formatLine, p.Line))
-		if p.Column > 0 {
-			parts = append(parts, fmt.Sprintf(formatCol, p.Column))
-		}
-	}
-	return strings.Join(parts, formatJoin)
+// FatalAt logs an error level message with annotation properties, and fails the program.
+func FatalAt(a Annotation, v ...interface{}) {
+	std.FatalAt(a, v...)
 }
 
-// Mask a term in the logs (will appear as '*' instead.)
+// Mask a term in the logs (will appear as '*' instead.) Multi-line terms are masked line by line.
 func Mask(term string) {
-	if !goaction.CI {
-		return
-	}
-	fmt.Println("::add-mask::" + term)
+	std.Mask(term)
+}
+
+// MaskSecret masks a secret in the logs. In addition to the secret itself, each line of a
+// multi-line secret and the URL encoded and base64 encoded forms of the secret are masked.
+func MaskSecret(secret string) {
+	std.MaskSecret(secret)
 }
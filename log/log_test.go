@@ -1,32 +1,43 @@
-package log
+package log_test
 
 import (
 	"bytes"
+	"errors"
 	"go/token"
+	"io"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/posener/goaction"
+	"github.com/posener/goaction/goactiontest"
+	"github.com/posener/goaction/log"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestLog(t *testing.T) {
-	old := goaction.CI
-	defer func() { goaction.CI = old }()
+	env := goactiontest.New(t)
+	defer env.Close()
 
 	t.Run("CI=true", func(t *testing.T) {
 		goaction.CI = true
-		initFormats()
 
 		want := `::debug::debugf foo
 printf foo
+::notice::noticef foo
 ::warning::warnf foo
 ::error::errorf foo
 ::debug file=foo.go,line=10,col=3::debugf foo
+::notice file=foo.go,line=10,col=3::noticef foo
 ::warning file=foo.go,line=10,col=3::warnf foo
 ::error file=foo.go,line=10,col=3::errorf foo
 ::debug file=foo.go::debugf foo
 ::warning file=foo.go::warnf foo
 ::error file=foo.go::errorf foo
+::notice file=foo.go,line=10,col=3,endLine=12,endColumn=5,title=Title::noticef foo
+::warning file=foo.go,line=10,endLine=12,title=Title::warnf foo
+::error title=Title::errorf foo
 `
 
 		assert.Equal(t, want, logThings())
@@ -34,18 +45,22 @@ printf foo
 
 	t.Run("CI=false", func(t *testing.T) {
 		goaction.CI = false
-		initFormats()
 
 		want := `debugf foo
 printf foo
+noticef foo
 warnf foo
 errorf foo
 foo.go+10:3: debugf foo
+foo.go+10:3: noticef foo
 foo.go+10:3: warnf foo
 foo.go+10:3: errorf foo
 foo.go: debugf foo
 foo.go: warnf foo
 foo.go: errorf foo
+foo.go+10:3-12:5: Title: noticef foo
+foo.go+10-12: Title: warnf foo
+Title: errorf foo
 `
 
 		assert.Equal(t, want, logThings())
@@ -54,22 +69,295 @@ foo.go: errorf foo
 
 func logThings() string {
 	var b bytes.Buffer
-	logger.SetOutput(&b)
+	log.SetOutput(&b)
 
-	Debugf("debugf %s", "foo")
-	Printf("printf %s", "foo")
-	Warnf("warnf %s", "foo")
-	Errorf("errorf %s", "foo")
+	log.Debugf("debugf %s", "foo")
+	log.Printf("printf %s", "foo")
+	log.Noticef("noticef %s", "foo")
+	log.Warnf("warnf %s", "foo")
+	log.Errorf("errorf %s", "foo")
 
 	p := token.Position{Filename: "foo.go", Line: 10, Column: 3}
-	DebugfFile(p, "debugf %s", "foo")
-	WarnfFile(p, "warnf %s", "foo")
-	ErrorfFile(p, "errorf %s", "foo")
+	log.DebugfFile(p, "debugf %s", "foo")
+	log.NoticefFile(p, "noticef %s", "foo")
+	log.WarnfFile(p, "warnf %s", "foo")
+	log.ErrorfFile(p, "errorf %s", "foo")
 
 	p = token.Position{Filename: "foo.go"}
-	DebugfFile(p, "debugf %s", "foo")
-	WarnfFile(p, "warnf %s", "foo")
-	ErrorfFile(p, "errorf %s", "foo")
+	log.DebugfFile(p, "debugf %s", "foo")
+	log.WarnfFile(p, "warnf %s", "foo")
+	log.ErrorfFile(p, "errorf %s", "foo")
+
+	log.NoticefAt(log.Annotation{
+		Title: "Title",
+		Start: token.Position{Filename: "foo.go", Line: 10, Column: 3},
+		End:   token.Position{Line: 12, Column: 5},
+	}, "noticef %s", "foo")
+	log.WarnfAt(log.Annotation{
+		Title: "Title",
+		Start: token.Position{Filename: "foo.go", Line: 10},
+		End:   token.Position{Line: 12},
+	}, "warnf %s", "foo")
+	log.ErrorfAt(log.Annotation{Title: "Title"}, "errorf %s", "foo")
 
 	return b.String()
 }
+
+func TestMask(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	log.Mask("line1\r\nline2\n\n")
+	log.MaskSecret("a b/c")
+	assert.Equal(t, []string{"line1", "line2", "a b/c", "a+b%2Fc", "a%20b%2Fc", "YSBiL2M="}, env.Masked())
+}
+
+func TestMaskSecretInput(t *testing.T) {
+	env := goactiontest.New(t, goactiontest.WithSecret("token", "a b/c"))
+	defer env.Close()
+
+	log.Printf("token: %s", goaction.Input("token"))
+
+	assert.Equal(t, []string{"a b/c", "a+b%2Fc", "a%20b%2Fc", "YSBiL2M="}, env.Masked())
+	// The secret is masked before it is logged.
+	assert.True(t, strings.HasSuffix(env.Log(), "::add-mask::YSBiL2M=\ntoken: a b/c\n"), env.Log())
+}
+
+func TestLogEscape(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	p := token.Position{Filename: "a,b:c.go", Line: 10}
+	log.ErrorfFile(p, "line1\nline2 %d%%\n", 100)
+	log.Mask("50%")
+
+	want := "::error file=a%2Cb%3Ac.go,line=10::line1%0Aline2 100%25\n::add-mask::50%25\n"
+	assert.Equal(t, want, env.Log())
+	assert.Equal(t, []goactiontest.Annotation{
+		{Level: "error", File: "a,b:c.go", Line: 10, Message: "line1\nline2 100%"},
+	}, env.Annotations())
+	assert.Equal(t, []string{"50%"}, env.Masked())
+}
+
+func TestWithCommandsStopped(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	log.WithCommandsStopped(func() {
+		log.Printf("::add-mask::untrusted")
+		log.Warnf("not annotated")
+	})
+	log.Warnf("annotated")
+
+	lines := strings.Split(strings.TrimSpace(env.Log()), "\n")
+	require.Len(t, lines, 5)
+	assert.Regexp(t, `^::stop-commands::[0-9a-f]{32}$`, lines[0])
+	assert.Equal(t, "::"+strings.TrimPrefix(lines[0], "::stop-commands::")+"::", lines[3])
+	assert.Empty(t, env.Masked())
+	assert.Equal(t, []goactiontest.Annotation{{Level: "warning", Message: "annotated"}}, env.Annotations())
+}
+
+func TestStopCommandsWriter(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	w := log.StopCommandsWriter(log.Writer())
+	_, err := io.WriteString(w, "::add-mask::untrusted")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	require.NoError(t, w.Close())
+	log.Mask("trusted")
+
+	assert.Equal(t, []string{"trusted"}, env.Masked())
+	assert.Equal(t, 1, strings.Count(env.Log(), "::stop-commands::"))
+
+	goaction.CI = false
+	var b bytes.Buffer
+	w = log.StopCommandsWriter(&b)
+	_, err = io.WriteString(w, "::add-mask::untrusted")
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	assert.Equal(t, "::add-mask::untrusted", b.String())
+}
+
+func TestGroup(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	t.Run("CI=true", func(t *testing.T) {
+		goaction.CI = true
+		var b bytes.Buffer
+		log.SetOutput(&b)
+
+		err := log.InGroup("title", func() error {
+			log.Printf("in group")
+			return errors.New("failed")
+		})
+		assert.EqualError(t, err, "failed")
+		log.Printf("out of group")
+
+		assert.Equal(t, "::group::title\nin group\n::endgroup::\nout of group\n", b.String())
+	})
+
+	t.Run("CI=false", func(t *testing.T) {
+		goaction.CI = false
+		var b bytes.Buffer
+		log.SetOutput(&b)
+
+		log.Group("outer")
+		log.Printf("in outer")
+		log.Group("inner")
+		log.Printf("in inner")
+		log.EndGroup()
+		log.EndGroup()
+		log.EndGroup() // Ending a group that was not started is ignored.
+		log.Printf("out of group")
+
+		assert.Regexp(t, `^▸ outer
+  in outer
+  ▸ inner
+    in inner
+  ◂ inner \(\d+(\.\d+)?[mµn]?s\)
+◂ outer \(\d+(\.\d+)?[mµn]?s\)
+out of group
+$`, b.String())
+	})
+}
+
+func TestRelativePaths(t *testing.T) {
+	wd, err := filepath.Abs(".")
+	require.NoError(t, err)
+	workspace := filepath.Dir(wd)
+	env := goactiontest.New(t, goactiontest.WithEnv("GITHUB_WORKSPACE", workspace))
+	defer env.Close()
+
+	for _, file := range []string{
+		"foo.go",
+		"./foo.go",
+		filepath.Join(wd, "foo.go"),
+		"/other/foo.go",
+		"../../foo.go",
+	} {
+		log.ErrorfFile(token.Position{Filename: file}, "error")
+	}
+	log.SetRoot(wd)
+	log.ErrorfFile(token.Position{Filename: "foo.go"}, "error")
+	log.SetRoot("")
+
+	want := `::error file=log/foo.go::error
+::error file=log/foo.go::error
+::error file=log/foo.go::error
+::error file=/other/foo.go::error
+::error file=../../foo.go::error
+::error file=foo.go::error
+`
+	assert.Equal(t, want, env.Log())
+}
+
+func TestBudget(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	for i := 1; i <= 12; i++ {
+		log.WarnfFile(token.Position{Filename: "foo.go", Line: i}, "warning %d", i)
+	}
+	log.ErrorfAt(log.Annotation{Title: "Title"}, "error")
+	log.Debugf("debug")
+
+	lines := strings.Split(strings.TrimSpace(env.Log()), "\n")
+	require.Len(t, lines, 14)
+	assert.Equal(t, "::warning file=foo.go,line=10::warning 10", lines[9])
+	assert.Equal(t, "warning: foo.go+11: warning 11", lines[10])
+	assert.Equal(t, "warning: foo.go+12: warning 12", lines[11])
+	assert.Equal(t, "::error title=Title::error", lines[12])
+	assert.Len(t, env.Annotations(), 12)
+
+	require.NoError(t, log.Flush())
+	summary := env.Summary()
+	assert.Contains(t, summary, "## Annotations\n\nGithub shows only 10 annotations of each level, all 13 annotations are listed below.\n\n")
+	assert.Contains(t, summary, "### Errors (1)\n\n| File | Line | Title | Message |\n| --- | --- | --- | --- |\n|  |  | Title | error |\n\n")
+	assert.Contains(t, summary, "### Warnings (12)\n\n")
+	assert.Contains(t, summary, "| foo.go | 12 |  | warning 12 |\n")
+	assert.NotContains(t, summary, "Notices")
+
+	// The annotations were flushed, and are not written again.
+	require.NoError(t, log.Flush())
+	assert.Equal(t, summary, env.Summary())
+
+	// The budget is kept after a flush, and only new annotations are flushed.
+	log.Warnf("warning")
+	assert.True(t, strings.HasSuffix(env.Log(), "\nwarning: warning\n"))
+	require.NoError(t, log.Flush())
+	more := strings.TrimPrefix(env.Summary(), summary)
+	assert.Contains(t, more, "## Annotations\n\n1 more annotations were logged after the 13 annotations that are listed above.\n\n")
+	assert.Contains(t, more, "### Warnings (1)\n\n| File | Line | Title | Message |\n| --- | --- | --- | --- |\n|  |  |  | warning |\n\n")
+	assert.NotContains(t, more, "Errors")
+}
+
+func TestBudgetEscape(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	for i := 0; i < 10; i++ {
+		log.Warnf("warning")
+	}
+	log.Warnf("line1\r\n::add-mask::line2")
+
+	assert.True(t, strings.HasSuffix(env.Log(), "\nwarning: line1%0D%0A::add-mask::line2\n"), env.Log())
+	assert.Empty(t, env.Masked())
+}
+
+func TestBudgetNotExceeded(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	log.Warnf("warning")
+	require.NoError(t, log.Flush())
+	assert.Empty(t, env.Summary())
+}
+
+func TestLogger(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+	goaction.CI = false
+
+	var ci, local bytes.Buffer
+	ciLogger := log.New(&ci, log.Options{Prefix: "[ci] ", Mode: log.ModeCI})
+	localLogger := log.New(&local, log.Options{Prefix: "[local] ", Mode: log.ModeLocal})
+
+	for _, l := range []*log.Logger{ciLogger, localLogger} {
+		l.Printf("printf %s", "foo")
+		l.WarnfFile(token.Position{Filename: "foo.go", Line: 10}, "warnf %s", "foo")
+		l.InGroup("group", func() error {
+			l.Errorf("errorf %s", "foo")
+			return nil
+		})
+		l.Mask("secret")
+	}
+
+	assert.Equal(t, `[ci] printf foo
+::warning file=foo.go,line=10::[ci] warnf foo
+::group::group
+::error::[ci] errorf foo
+::endgroup::
+::add-mask::secret
+`, ci.String())
+
+	assert.Regexp(t, `^\[local\] printf foo
+foo.go\+10: \[local\] warnf foo
+▸ group
+  \[local\] errorf foo
+◂ group \(\d+(\.\d+)?[mµn]?s\)
+$`, local.String())
+
+	// The default logger is not affected.
+	assert.Empty(t, env.Log())
+}
+
+func TestDefault(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	log.Default().Warnf("warnf")
+	assert.Equal(t, []goactiontest.Annotation{{Level: "warning", Message: "warnf"}}, env.Annotations())
+}
@@ -0,0 +1,229 @@
+package log
+
+import (
+	"fmt"
+	"go/token"
+	"io"
+	"log"
+	"strings"
+	"sync/atomic"
+
+	"github.com/posener/goaction"
+	"github.com/posener/goaction/internal/command"
+)
+
+// Mode is the formatting mode of a Logger.
+type Mode int
+
+const (
+	// ModeAuto formats the logs in CI mode when goaction.CI is true, and in local mode otherwise.
+	ModeAuto Mode = iota
+	// ModeCI formats the logs as workflow commands, which are interpreted by the Github runner.
+	ModeCI
+	// ModeLocal formats the logs for a terminal.
+	ModeLocal
+)
+
+// Options for a Logger.
+type Options struct {
+	// Prefix is added to the messages of the logger.
+	Prefix string
+	// Mode is the formatting mode of the logger.
+	Mode Mode
+}
+
+// Escapes line breaks in plain lines of annotations beyond the budget, such that a message can't
+// start a line which the runner interprets as a workflow command.
+var lineEscaper = strings.NewReplacer("\r", "%0D", "\n", "%0A")
+
+// Logger logs in Github action environment. The package level functions use a default logger,
+// and a Logger can be created explicitly in order to log to a different writer, with a prefix or
+// with a fixed formatting mode. For example, a library can accept a Logger, and tests can capture
+// its output.
+//
+// A Logger can be used concurrently. The annotations budget (see Flush) and the error count (see
+// ErrorCount) are shared by all loggers, as they are limited by Github per step.
+type Logger struct {
+	l      *log.Logger
+	prefix string
+	mode   Mode
+	groups groupStack
+}
+
+// New returns a logger that writes to w.
+func New(w io.Writer, opts Options) *Logger {
+	return &Logger{
+		l:      log.New(w, "", 0),
+		prefix: opts.Prefix,
+		mode:   opts.Mode,
+	}
+}
+
+// SetOutput sets the output destination of the logger.
+func (l *Logger) SetOutput(w io.Writer) {
+	l.l.SetOutput(w)
+}
+
+// Writer returns the output destination of the logger.
+func (l *Logger) Writer() io.Writer {
+	return l.l.Writer()
+}
+
+// ci returns whether the logger formats the logs in CI mode.
+func (l *Logger) ci() bool {
+	switch l.mode {
+	case ModeCI:
+		return true
+	case ModeLocal:
+		return false
+	default:
+		return goaction.CI
+	}
+}
+
+// format returns the log line of a message. In CI mode, the message is encoded as a workflow
+// command in the level. Annotations beyond the Github budget are formatted as plain lines.
+func (l *Logger) format(lv level, a Annotation, msg string) string {
+	// Like the standard library logger, a trailing newline is optional.
+	msg = l.prefix + strings.TrimSuffix(msg, "\n")
+	if lv == levelError {
+		atomic.AddInt64(&errorCount, 1)
+	}
+	if !l.ci() {
+		return a.plain(msg)
+	}
+	if lv != levelDebug && !budget.add(lv, a, msg) {
+		return lineEscaper.Replace(string(lv) + ": " + a.plain(msg))
+	}
+	return command.New(string(lv), msg, a.properties()...).String()
+}
+
+// command logs a workflow command.
+func (l *Logger) command(name, message string) {
+	l.l.Print(command.New(name, message).String())
+}
+
+// Printf logs an info level message.
+func (l *Logger) Printf(format string, args ...interface{}) {
+	l.l.Print(l.prefix + fmt.Sprintf(format, args...))
+}
+
+// Debugf logs a debug level message. To view these logs, set secret ACTIONS_STEP_DEBUG=true at
+// https://github.com/<repo>/settings/secrets/new.
+func (l *Logger) Debugf(format string, args ...interface{}) {
+	l.DebugfAt(Annotation{}, format, args...)
+}
+
+// DebugfFile logs a debug level message with a file location.
+func (l *Logger) DebugfFile(p token.Position, format string, args ...interface{}) {
+	l.DebugfAt(Annotation{Start: p}, format, args...)
+}
+
+// DebugfAt logs a debug level message with annotation properties.
+func (l *Logger) DebugfAt(a Annotation, format string, args ...interface{}) {
+	l.l.Print(l.format(levelDebug, a, fmt.Sprintf(format, args...)))
+}
+
+// Noticef logs a notice level message.
+func (l *Logger) Noticef(format string, args ...interface{}) {
+	l.NoticefAt(Annotation{}, format, args...)
+}
+
+// NoticefFile logs a notice level message with a file location.
+func (l *Logger) NoticefFile(p token.Position, format string, args ...interface{}) {
+	l.NoticefAt(Annotation{Start: p}, format, args...)
+}
+
+// NoticefAt logs a notice level message with annotation properties.
+func (l *Logger) NoticefAt(a Annotation, format string, args ...interface{}) {
+	l.l.Print(l.format(levelNotice, a, fmt.Sprintf(format, args...)))
+}
+
+// Warnf logs a warning level message.
+func (l *Logger) Warnf(format string, args ...interface{}) {
+	l.WarnfAt(Annotation{}, format, args...)
+}
+
+// WarnfFile logs a warning level message with a file location.
+func (l *Logger) WarnfFile(p token.Position, format string, args ...interface{}) {
+	l.WarnfAt(Annotation{Start: p}, format, args...)
+}
+
+// WarnfAt logs a warning level message with annotation properties.
+func (l *Logger) WarnfAt(a Annotation, format string, args ...interface{}) {
+	l.l.Print(l.format(levelWarn, a, fmt.Sprintf(format, args...)))
+}
+
+// Errorf logs an error level message.
+func (l *Logger) Errorf(format string, args ...interface{}) {
+	l.ErrorfAt(Annotation{}, format, args...)
+}
+
+// ErrorfFile logs an error level message with a file location.
+func (l *Logger) ErrorfFile(p token.Position, format string, args ...interface{}) {
+	l.ErrorfAt(Annotation{Start: p}, format, args...)
+}
+
+// ErrorfAt logs an error level message with annotation properties.
+func (l *Logger) ErrorfAt(a Annotation, format string, args ...interface{}) {
+	l.l.Print(l.format(levelError, a, fmt.Sprintf(format, args...)))
+}
+
+// Fatalf logs an error level message, and fails the program.
+func (l *Logger) Fatalf(format string, args ...interface{}) {
+	l.FatalfAt(Annotation{}, format, args...)
+}
+
+// FatalfFile logs an error level message with a file location, and fails the program. It is a
+// shorthand for FatalfAt with an annotation that starts at the location.
+func (l *Logger) FatalfFile(p token.Position, format string, args ...interface{}) {
+	l.FatalfAt(Annotation{Start: p}, format, args...)
+}
+
+// FatalfAt logs an error level message with annotation properties, and fails the program.
+func (l *Logger) FatalfAt(a Annotation, format string, args ...interface{}) {
+	l.fatal(a, fmt.Sprintf(format, args...))
+}
+
+// Fatal logs an error level message, and fails the program.
+func (l *Logger) Fatal(v ...interface{}) {
+	l.FatalAt(Annotation{}, v...)
+}
+
+// FatalFile logs an error level message with a file location, and fails the program. It is a
+// shorthand for FatalAt with an annotation that starts at the location.
+func (l *Logger) FatalFile(p token.Position, v ...interface{}) {
+	l.FatalAt(Annotation{Start: p}, v...)
+}
+
+// FatalAt logs an error level message with annotation properties, and fails the program.
+func (l *Logger) FatalAt(a Annotation, v ...interface{}) {
+	l.fatal(a, fmt.Sprint(v...))
+}
+
+// fatal logs an error level message, flushes the logs and fails the program.
+func (l *Logger) fatal(a Annotation, msg string) {
+	l.l.Print(l.format(levelError, a, msg))
+	flush()
+	exit(1)
+}
+
+// Mask a term in the logs (will appear as '*' instead.) Multi-line terms are masked line by line.
+func (l *Logger) Mask(term string) {
+	l.mask(command.Mask(term))
+}
+
+// MaskSecret masks a secret in the logs. In addition to the secret itself, each line of a
+// multi-line secret and the URL encoded and base64 encoded forms of the secret are masked.
+func (l *Logger) MaskSecret(secret string) {
+	l.mask(command.MaskSecret(secret))
+}
+
+func (l *Logger) mask(cmds []command.Command) {
+	if !l.ci() {
+		return
+	}
+	for _, cmd := range cmds {
+		l.l.Print(cmd.String())
+	}
+}
@@ -0,0 +1,174 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/posener/goaction"
+	"github.com/posener/goaction/internal/command"
+)
+
+// Matchers is the content of a problem matcher file. Problem matchers scan the output of the steps
+// of a job with regular expressions, and create annotations from the lines that match.
+// See https://github.com/actions/toolkit/blob/main/docs/problem-matchers.md.
+type Matchers struct {
+	ProblemMatcher []Matcher `json:"problemMatcher"`
+}
+
+// Matcher is a problem matcher.
+type Matcher struct {
+	// Owner identifies the matcher, and is used to remove it.
+	Owner string `json:"owner"`
+	// Severity is the default severity of the matched problems: "error" or "warning".
+	Severity string `json:"severity,omitempty"`
+	// Pattern is a list of patterns that match consecutive lines. The properties of the problem
+	// are collected from all the patterns.
+	Pattern []Pattern `json:"pattern"`
+}
+
+// Pattern is a regular expression that matches a line of a problem. The other fields are indices
+// of the regular expression groups which contain the properties of the problem. A zero index means
+// that the property is not matched.
+type Pattern struct {
+	Regexp   string `json:"regexp"`
+	File     int    `json:"file,omitempty"`
+	FromPath int    `json:"fromPath,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+	Severity int    `json:"severity,omitempty"`
+	Code     int    `json:"code,omitempty"`
+	Message  int    `json:"message,omitempty"`
+	// Loop is allowed only in the last pattern. It matches the pattern repeatedly, and creates a
+	// problem from each matching line.
+	Loop bool `json:"loop,omitempty"`
+}
+
+// GoBuildMatcher returns a problem matcher for the errors of `go build`. It matches
+// "file.go:line:col: message" lines.
+func GoBuildMatcher() Matcher {
+	return Matcher{
+		Owner:    "go-build",
+		Severity: "error",
+		Pattern: []Pattern{{
+			Regexp:  `^(?:\./)?([^\s:]+\.go):(\d+)(?::(\d+))?: (.*)$`,
+			File:    1,
+			Line:    2,
+			Column:  3,
+			Message: 4,
+		}},
+	}
+}
+
+// GoVetMatcher returns a problem matcher for the diagnostics of `go vet`, which are reported as
+// warnings. The output of go vet has the same format as the output of go build, so only one of
+// GoVetMatcher and GoBuildMatcher should be added at a time.
+func GoVetMatcher() Matcher {
+	return Matcher{
+		Owner:    "go-vet",
+		Severity: "warning",
+		Pattern: []Pattern{{
+			Regexp:  `^(?:vet: )?(?:\./)?([^\s:]+\.go):(\d+)(?::(\d+))?: (.*)$`,
+			File:    1,
+			Line:    2,
+			Column:  3,
+			Message: 4,
+		}},
+	}
+}
+
+// GoTestMatcher returns a problem matcher for the failures of `go test`. It matches the t.Error
+// and t.Fatal lines that follow a "--- FAIL" line, and uses the test name as the problem code.
+//
+// The matcher is applied by the runner to the raw output, so it supports only the output of
+// `go test` without the -v flag, in which the t.Error and t.Fatal lines follow the "--- FAIL" line.
+// The file paths in the output are file names in the tested package directory, so the problems are
+// annotated at the right files only for the package in the workspace root directory. For other
+// cases, the report subcommand of goaction annotates the failures from `go test -json` output.
+func GoTestMatcher() Matcher {
+	return Matcher{
+		Owner:    "go-test",
+		Severity: "error",
+		Pattern: []Pattern{
+			{
+				Regexp: `^\s*--- FAIL: (\S+)`,
+				Code:   1,
+			},
+			{
+				Regexp:  `^\s+([^\s:]+\.go):(\d+): (.*)$`,
+				File:    1,
+				Line:    2,
+				Message: 3,
+				Loop:    true,
+			},
+		},
+	}
+}
+
+// WriteMatchers writes a problem matcher file.
+func WriteMatchers(path string, matchers ...Matcher) error {
+	b, err := json.MarshalIndent(Matchers{ProblemMatcher: matchers}, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, b, 0644)
+}
+
+// AddMatchers writes the problem matchers to a file in the runner temporary directory and adds
+// them. For example, the following adds the Go problem matchers to the steps that follow:
+//
+//	err := log.AddMatchers(log.GoBuildMatcher(), log.GoTestMatcher())
+func AddMatchers(matchers ...Matcher) error {
+	return std.AddMatchers(matchers...)
+}
+
+// AddMatcher adds the problem matchers in a file. The matchers apply to the output of the current
+// step and of the steps that follow, until they are removed.
+func AddMatcher(path string) {
+	std.AddMatcher(path)
+}
+
+// RemoveMatcher removes the problem matcher of the owner.
+func RemoveMatcher(owner string) {
+	std.RemoveMatcher(owner)
+}
+
+// AddMatchers writes the problem matchers to a file in the runner temporary directory and adds
+// them with the logger. In local mode, nothing is done.
+func (l *Logger) AddMatchers(matchers ...Matcher) error {
+	if !l.ci() {
+		return nil
+	}
+	f, err := ioutil.TempFile(goaction.RunnerTemp, "goaction-matcher-*.json")
+	if err != nil {
+		return fmt.Errorf("creating problem matcher file: %s", err)
+	}
+	f.Close()
+	err = WriteMatchers(f.Name(), matchers...)
+	if err != nil {
+		return fmt.Errorf("writing problem matcher file: %s", err)
+	}
+	l.AddMatcher(f.Name())
+	return nil
+}
+
+// AddMatcher adds the problem matchers in a file with the logger. In local mode, nothing is done.
+func (l *Logger) AddMatcher(path string) {
+	if !l.ci() {
+		return
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	l.command("add-matcher", path)
+}
+
+// RemoveMatcher removes the problem matcher of the owner with the logger. In local mode, nothing
+// is done.
+func (l *Logger) RemoveMatcher(owner string) {
+	if !l.ci() {
+		return
+	}
+	l.l.Print(command.New("remove-matcher", "", command.Property{Key: "owner", Value: owner}).String())
+}
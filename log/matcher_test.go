@@ -0,0 +1,160 @@
+package log_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/posener/goaction"
+	"github.com/posener/goaction/goactiontest"
+	"github.com/posener/goaction/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchers(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	err := log.AddMatchers(log.GoBuildMatcher(), log.GoTestMatcher())
+	require.NoError(t, err)
+	log.RemoveMatcher("go-test")
+
+	lines := strings.Split(strings.TrimSpace(env.Log()), "\n")
+	require.Len(t, lines, 2)
+	require.True(t, strings.HasPrefix(lines[0], "::add-matcher::"+goaction.RunnerTemp))
+	assert.Equal(t, "::remove-matcher owner=go-test::", lines[1])
+
+	b, err := ioutil.ReadFile(strings.TrimPrefix(lines[0], "::add-matcher::"))
+	require.NoError(t, err)
+	var got log.Matchers
+	require.NoError(t, json.Unmarshal(b, &got))
+	assert.Equal(t, log.Matchers{ProblemMatcher: []log.Matcher{log.GoBuildMatcher(), log.GoTestMatcher()}}, got)
+}
+
+func TestMatchersLocal(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+	goaction.CI = false
+
+	require.NoError(t, log.AddMatchers(log.GoBuildMatcher()))
+	log.AddMatcher("matcher.json")
+	log.RemoveMatcher("go-build")
+	assert.Empty(t, env.Log())
+}
+
+func TestGoMatchers(t *testing.T) {
+	tests := []struct {
+		matcher log.Matcher
+		line    string
+		want    []string
+	}{
+		{
+			matcher: log.GoBuildMatcher(),
+			line:    "./main.go:10:2: undefined: x",
+			want:    []string{"main.go", "10", "2", "undefined: x"},
+		},
+		{
+			matcher: log.GoBuildMatcher(),
+			line:    "pkg/foo.go:3: syntax error",
+			want:    []string{"pkg/foo.go", "3", "", "syntax error"},
+		},
+		{
+			matcher: log.GoVetMatcher(),
+			line:    "vet: ./main.go:10:2: x declared and not used",
+			want:    []string{"main.go", "10", "2", "x declared and not used"},
+		},
+		{
+			matcher: log.GoBuildMatcher(),
+			line:    "    main_test.go:10: failed",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			m := regexp.MustCompile(tt.matcher.Pattern[0].Regexp).FindStringSubmatch(tt.line)
+			if tt.want == nil {
+				assert.Nil(t, m)
+				return
+			}
+			require.NotNil(t, m)
+			assert.Equal(t, tt.want, m[1:])
+		})
+	}
+
+	test := log.GoTestMatcher()
+	require.Len(t, test.Pattern, 2)
+	fail := regexp.MustCompile(test.Pattern[0].Regexp).FindStringSubmatch("    --- FAIL: TestFoo/bar (0.00s)")
+	require.NotNil(t, fail)
+	assert.Equal(t, "TestFoo/bar", fail[test.Pattern[0].Code])
+	msg := regexp.MustCompile(test.Pattern[1].Regexp).FindStringSubmatch("        foo_test.go:12: got 1, want 2")
+	require.NotNil(t, msg)
+	assert.Equal(t, []string{"foo_test.go", "12", "got 1, want 2"}, msg[1:])
+}
+
+func TestGoTestMatcherOutput(t *testing.T) {
+	// Output of go test for the package in the root directory.
+	out := `--- FAIL: TestA (0.00s)
+    a_test.go:6: got 1, want 2
+    a_test.go:7: got 3, want 4
+--- FAIL: TestB (0.00s)
+    --- FAIL: TestB/sub (0.00s)
+        b_test.go:7: failed
+FAIL
+FAIL	example.com/m	0.004s
+FAIL
+`
+	assert.Equal(t, []string{
+		"a_test.go:6: TestA: got 1, want 2",
+		"a_test.go:7: TestA: got 3, want 4",
+		"b_test.go:7: TestB/sub: failed",
+	}, goTestProblems(out))
+
+	// Output of go test -v for multiple packages, in which the t.Error and t.Fatal lines come
+	// before the "--- FAIL" line, is not supported.
+	out = `=== RUN   TestA
+    a_test.go:6: got 1, want 2
+--- FAIL: TestA (0.00s)
+=== RUN   TestOK
+--- PASS: TestOK (0.00s)
+FAIL
+FAIL	example.com/m/a	0.004s
+=== RUN   TestB
+=== RUN   TestB/sub
+    b_test.go:7: failed
+--- FAIL: TestB (0.00s)
+    --- FAIL: TestB/sub (0.00s)
+FAIL
+FAIL	example.com/m/b	0.004s
+FAIL
+`
+	assert.Empty(t, goTestProblems(out))
+}
+
+// goTestProblems applies the go test problem matcher to output like the Github runner does: the
+// first pattern matches a line, and then the looping pattern matches the lines that follow it. A
+// line that does not match the looping pattern is matched again with the first pattern.
+func goTestProblems(out string) []string {
+	m := log.GoTestMatcher()
+	fail, msg := m.Pattern[0], m.Pattern[1]
+	failRe, msgRe := regexp.MustCompile(fail.Regexp), regexp.MustCompile(msg.Regexp)
+
+	var (
+		problems []string
+		code     string
+	)
+	for _, line := range strings.Split(out, "\n") {
+		if code != "" {
+			if g := msgRe.FindStringSubmatch(line); g != nil {
+				problems = append(problems, g[msg.File]+":"+g[msg.Line]+": "+code+": "+g[msg.Message])
+				continue
+			}
+			code = ""
+		}
+		if g := failRe.FindStringSubmatch(line); g != nil {
+			code = g[fail.Code]
+		}
+	}
+	return problems
+}
@@ -0,0 +1,92 @@
+package log
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+
+	"github.com/posener/goaction/internal/command"
+)
+
+// WithCommandsStopped runs a function while workflow commands are not processed by the runner. It
+// should be used when printing untrusted text, such as the body of a pull request or the output of
+// a program, which may contain strings that would be interpreted as workflow commands. Logs that
+// are printed by the function are not annotated.
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#stopping-and-starting-workflow-commands.
+func WithCommandsStopped(f func()) {
+	std.WithCommandsStopped(f)
+}
+
+// WithCommandsStopped runs a function while workflow commands are not processed by the runner.
+// The stop and resume commands are written to the output of the logger.
+func (l *Logger) WithCommandsStopped(f func()) {
+	if !l.ci() {
+		f()
+		return
+	}
+	token := stopToken()
+	l.command("stop-commands", token)
+	defer l.command(token, "")
+	f()
+}
+
+// StopCommandsWriter returns a writer that writes to w while workflow commands are not processed
+// by the runner. Workflow commands are resumed when the writer is closed. It is useful for passing
+// untrusted output of programs to the logs:
+//
+//	w := log.StopCommandsWriter(os.Stdout)
+//	defer w.Close()
+//	cmd.Stdout = w
+func StopCommandsWriter(w io.Writer) io.WriteCloser {
+	return std.StopCommandsWriter(w)
+}
+
+// StopCommandsWriter returns a writer that writes to w while workflow commands are not processed
+// by the runner. Workflow commands are stopped only if the logger is in CI mode.
+func (l *Logger) StopCommandsWriter(w io.Writer) io.WriteCloser {
+	if !l.ci() {
+		return nopCloser{w}
+	}
+	token := stopToken()
+	fmt.Fprintln(w, command.New("stop-commands", token))
+	return &stopWriter{w: w, token: token}
+}
+
+type stopWriter struct {
+	w      io.Writer
+	token  string
+	closed bool
+}
+
+func (s *stopWriter) Write(b []byte) (int, error) {
+	return s.w.Write(b)
+}
+
+// Close resumes the workflow commands.
+func (s *stopWriter) Close() error {
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+	// Make sure that the resume command starts in a new line.
+	_, err := fmt.Fprintf(s.w, "\n%s\n", command.New(s.token, ""))
+	return err
+}
+
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
+
+// stopToken returns a random token for stopping workflow commands. The token must not be guessable
+// such that the untrusted text can't resume the workflow commands.
+func stopToken() string {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		panic(fmt.Sprintf("failed generating stop commands token: %s", err))
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,131 @@
+package goaction
+
+// pullRequestPayload holds the pull request related fields of the payloads of all the events that
+// are related to pull requests.
+type pullRequestPayload struct {
+	PullRequest *pullRequest `json:"pull_request"`
+	Issue       *struct {
+		Number int `json:"number"`
+		// PullRequest is only set for issues that are pull requests.
+		PullRequest *struct{} `json:"pull_request"`
+	} `json:"issue"`
+}
+
+type pullRequest struct {
+	Number int            `json:"number"`
+	Head   pullRequestRef `json:"head"`
+	Base   pullRequestRef `json:"base"`
+}
+
+type pullRequestRef struct {
+	Ref  string `json:"ref"`
+	SHA  string `json:"sha"`
+	Repo struct {
+		FullName string `json:"full_name"`
+	} `json:"repo"`
+}
+
+// pullRequestEvents are the events that their payload contains a "pull_request" field.
+var pullRequestEvents = map[EventType]bool{
+	EventPullRequest:              true,
+	EventPullRequestTarget:        true,
+	EventPullRequestReview:        true,
+	EventPullRequestReviewComment: true,
+}
+
+// PrNum returns pull request number for PR flow or -1 in other flows.
+func PrNum() int {
+	return current().PrNum()
+}
+
+// HeadSHA returns the SHA of the head commit of the pull request for PR flow, or an empty string
+// in other flows. Unlike SHA, which is the commit of the merge of the pull request into the base
+// branch, this is the last commit of the pull request branch.
+func HeadSHA() string {
+	return current().HeadSHA()
+}
+
+// BaseSHA returns the SHA of the commit of the base branch of the pull request for PR flow, or an
+// empty string in other flows.
+func BaseSHA() string {
+	return current().BaseSHA()
+}
+
+// HeadRepo returns the full name of the repository of the pull request branch for PR flow, or an
+// empty string in other flows. For example, octocat/Hello-World. It differs from Repository for
+// pull requests from forks.
+func HeadRepo() string {
+	return current().HeadRepo()
+}
+
+// BaseRef returns the name of the base branch of the pull request for PR flow, or an empty string
+// in other flows.
+func BaseRef() string {
+	return current().BaseRef()
+}
+
+// PrNum returns pull request number for PR flow or -1 in other flows. The PR flows are triggered by
+// pull_request, pull_request_target, pull_request_review, pull_request_review_comment and
+// issue_comment on a pull request.
+func (c *Context) PrNum() int {
+	if pr := c.pullRequest(); pr != nil {
+		return pr.Number
+	}
+	if c.Event == EventIssueComment {
+		var p pullRequestPayload
+		if err := c.decodeEventInfo(&p); err == nil && p.Issue != nil && p.Issue.PullRequest != nil {
+			return p.Issue.Number
+		}
+	}
+	if c.Event == EventPullRequest {
+		// Fallback to the ref, which is in the form: "refs/pull/:prNumber/merge"
+		// See https://help.github.com/en/actions/reference/events-that-trigger-workflows#pull-request-event-pull_request
+		return ParseRef(c.Ref).PrNum()
+	}
+	return -1
+}
+
+// HeadSHA returns the SHA of the head commit of the pull request. See HeadSHA.
+func (c *Context) HeadSHA() string {
+	if pr := c.pullRequest(); pr != nil {
+		return pr.Head.SHA
+	}
+	return ""
+}
+
+// BaseSHA returns the SHA of the commit of the base branch of the pull request. See BaseSHA.
+func (c *Context) BaseSHA() string {
+	if pr := c.pullRequest(); pr != nil {
+		return pr.Base.SHA
+	}
+	return ""
+}
+
+// HeadRepo returns the full name of the repository of the pull request branch. See HeadRepo.
+func (c *Context) HeadRepo() string {
+	if pr := c.pullRequest(); pr != nil {
+		return pr.Head.Repo.FullName
+	}
+	return ""
+}
+
+// BaseRef returns the name of the base branch of the pull request. See BaseRef.
+func (c *Context) BaseRef() string {
+	if pr := c.pullRequest(); pr != nil {
+		return pr.Base.Ref
+	}
+	return ""
+}
+
+// pullRequest returns the pull request from the event payload, or nil if the event is not a pull
+// request event.
+func (c *Context) pullRequest() *pullRequest {
+	if !pullRequestEvents[c.Event] {
+		return nil
+	}
+	var p pullRequestPayload
+	if err := c.decodeEventInfo(&p); err != nil {
+		return nil
+	}
+	return p.PullRequest
+}
@@ -0,0 +1,64 @@
+package goaction
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pullRequestPayloadJSON = `{
+	"number": 42,
+	"pull_request": {
+		"number": 42,
+		"head": {"ref": "feature", "sha": "head-sha", "repo": {"full_name": "fork/goaction"}},
+		"base": {"ref": "main", "sha": "base-sha", "repo": {"full_name": "posener/goaction"}}
+	}
+}`
+
+func TestPullRequest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "pullrequest")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	tests := []struct {
+		event       EventType
+		payload     string
+		ref         string
+		wantNum     int
+		wantHeadSHA string
+	}{
+		{event: EventPullRequest, payload: pullRequestPayloadJSON, wantNum: 42, wantHeadSHA: "head-sha"},
+		{event: EventPullRequestTarget, payload: pullRequestPayloadJSON, wantNum: 42, wantHeadSHA: "head-sha"},
+		{event: EventPullRequestReview, payload: pullRequestPayloadJSON, wantNum: 42, wantHeadSHA: "head-sha"},
+		{event: EventPullRequestReviewComment, payload: pullRequestPayloadJSON, wantNum: 42, wantHeadSHA: "head-sha"},
+		{event: EventIssueComment, payload: `{"issue": {"number": 43, "pull_request": {}}}`, wantNum: 43},
+		{event: EventIssueComment, payload: `{"issue": {"number": 44}}`, wantNum: -1},
+		{event: EventPush, payload: `{}`, ref: "refs/heads/main", wantNum: -1},
+		// Without a payload, the number is taken from the ref.
+		{event: EventPullRequest, payload: ``, ref: "refs/pull/45/merge", wantNum: 45},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.event), func(t *testing.T) {
+			eventPath := filepath.Join(dir, "event.json")
+			require.NoError(t, ioutil.WriteFile(eventPath, []byte(tt.payload), 0644))
+
+			c := FromMap(map[string]string{
+				"GITHUB_EVENT_NAME": string(tt.event),
+				"GITHUB_EVENT_PATH": eventPath,
+				"GITHUB_REF":        tt.ref,
+			})
+			assert.Equal(t, tt.wantNum, c.PrNum())
+			assert.Equal(t, tt.wantHeadSHA, c.HeadSHA())
+			if tt.wantHeadSHA != "" {
+				assert.Equal(t, "base-sha", c.BaseSHA())
+				assert.Equal(t, "fork/goaction", c.HeadRepo())
+				assert.Equal(t, "main", c.BaseRef())
+			}
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package goaction
+
+import (
+	"strconv"
+	"strings"
+)
+
+// RefKind is the kind of a git ref.
+type RefKind string
+
+// All ref kinds.
+const (
+	RefUnknown    RefKind = "unknown"
+	RefBranch     RefKind = "branch"
+	RefTag        RefKind = "tag"
+	RefPull       RefKind = "pull"
+	RefMergeGroup RefKind = "merge-group"
+)
+
+const (
+	refHeads      = "refs/heads/"
+	refTags       = "refs/tags/"
+	refPull       = "refs/pull/"
+	refMergeQueue = "refs/heads/gh-readonly-queue/"
+)
+
+// GitRef is a parsed git ref.
+type GitRef struct {
+	// Kind of the ref.
+	Kind RefKind
+	// Name is the short name of the ref. For example, feature/foo for refs/heads/feature/foo,
+	// v1.0.0 for refs/tags/v1.0.0 and 42/merge for refs/pull/42/merge.
+	Name string
+	// Full is the full name of the ref. For example, refs/heads/feature/foo.
+	Full string
+}
+
+// ParseRef parses a git ref, as given in the GITHUB_REF environment variable.
+func ParseRef(ref string) GitRef {
+	r := GitRef{Kind: RefUnknown, Name: ref, Full: ref}
+	switch {
+	case strings.HasPrefix(ref, refMergeQueue):
+		// Merge queue refs are branches of the form:
+		// refs/heads/gh-readonly-queue/<base branch>/pr-<number>-<sha>.
+		r.Kind = RefMergeGroup
+		r.Name = strings.TrimPrefix(ref, refHeads)
+	case strings.HasPrefix(ref, refHeads):
+		r.Kind = RefBranch
+		r.Name = strings.TrimPrefix(ref, refHeads)
+	case strings.HasPrefix(ref, refTags):
+		r.Kind = RefTag
+		r.Name = strings.TrimPrefix(ref, refTags)
+	case strings.HasPrefix(ref, refPull):
+		r.Kind = RefPull
+		r.Name = strings.TrimPrefix(ref, refPull)
+	}
+	return r
+}
+
+// PrNum returns the pull request number of a pull request ref or -1 for other refs.
+func (r GitRef) PrNum() int {
+	if r.Kind != RefPull {
+		return -1
+	}
+	// Name is in the form: ":prNumber/merge"
+	num, err := strconv.Atoi(strings.Split(r.Name, "/")[0])
+	if err != nil {
+		return -1
+	}
+	return num
+}
+
+// String returns the full name of the ref.
+func (r GitRef) String() string {
+	return r.Full
+}
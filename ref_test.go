@@ -0,0 +1,77 @@
+package goaction
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseRef(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		ref       string
+		want      GitRef
+		wantPrNum int
+	}{
+		{
+			ref:       "",
+			want:      GitRef{Kind: RefUnknown},
+			wantPrNum: -1,
+		},
+		{
+			ref:       "refs/heads/main",
+			want:      GitRef{Kind: RefBranch, Name: "main", Full: "refs/heads/main"},
+			wantPrNum: -1,
+		},
+		{
+			ref:       "refs/heads/feature/foo",
+			want:      GitRef{Kind: RefBranch, Name: "feature/foo", Full: "refs/heads/feature/foo"},
+			wantPrNum: -1,
+		},
+		{
+			ref:       "refs/tags/v1.0.0",
+			want:      GitRef{Kind: RefTag, Name: "v1.0.0", Full: "refs/tags/v1.0.0"},
+			wantPrNum: -1,
+		},
+		{
+			ref:       "refs/pull/42/merge",
+			want:      GitRef{Kind: RefPull, Name: "42/merge", Full: "refs/pull/42/merge"},
+			wantPrNum: 42,
+		},
+		{
+			ref:       "refs/heads/gh-readonly-queue/main/pr-42-abcd",
+			want:      GitRef{Kind: RefMergeGroup, Name: "gh-readonly-queue/main/pr-42-abcd", Full: "refs/heads/gh-readonly-queue/main/pr-42-abcd"},
+			wantPrNum: -1,
+		},
+		{
+			ref:       "refs/notes/foo",
+			want:      GitRef{Kind: RefUnknown, Name: "refs/notes/foo", Full: "refs/notes/foo"},
+			wantPrNum: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			got := ParseRef(tt.ref)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantPrNum, got.PrNum())
+		})
+	}
+}
+
+func TestBranchTag(t *testing.T) {
+	t.Parallel()
+
+	c := FromMap(map[string]string{"GITHUB_REF": "refs/heads/feature/foo"})
+	assert.Equal(t, "feature/foo", c.Branch())
+	assert.Equal(t, "", c.Tag())
+
+	c = FromMap(map[string]string{"GITHUB_REF": "refs/tags/v1.0.0"})
+	assert.Equal(t, "", c.Branch())
+	assert.Equal(t, "v1.0.0", c.Tag())
+
+	c = FromMap(map[string]string{})
+	assert.Equal(t, "", c.Branch())
+	assert.Equal(t, "", c.Tag())
+}
@@ -0,0 +1,99 @@
+package goaction
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/posener/goaction/internal/command"
+	"github.com/posener/goaction/internal/envfile"
+)
+
+// ActionPhase is a phase of a Docker action run.
+// See https://docs.github.com/en/actions/creating-actions/metadata-syntax-for-github-actions#runs-for-docker-container-actions.
+type ActionPhase string
+
+// All action phases.
+const (
+	// PhasePre runs before the main phase, when `pre-entrypoint` is defined.
+	PhasePre ActionPhase = "pre"
+	// PhaseMain is the main entrypoint of the action.
+	PhaseMain ActionPhase = "main"
+	// PhasePost runs at the end of the job, when `post-entrypoint` is defined.
+	PhasePost ActionPhase = "post"
+)
+
+// Names of the executables of the pre and post phases. The goaction generated Dockerfile links the
+// action binary to them, and uses them as the `pre-entrypoint` and `post-entrypoint` of the action.
+const (
+	PreExecutable  = "action-pre"
+	PostExecutable = "action-post"
+)
+
+// Phase returns the phase in which the action is currently running. The phase is taken from the
+// GOACTION_PHASE environment variable if it is set. Otherwise, it is inferred from the name of
+// the executable, which is PreExecutable in the pre phase and PostExecutable in the post phase.
+//
+// An error is returned if GOACTION_PHASE is not one of "pre", "main" or "post", as the action can't
+// tell what it should do.
+func Phase() (ActionPhase, error) {
+	return current().Phase()
+}
+
+// Phase returns the phase in which the action is currently running. See Phase.
+func (c *Context) Phase() (ActionPhase, error) {
+	if phase := c.getenv("GOACTION_PHASE"); phase != "" {
+		switch p := ActionPhase(phase); p {
+		case PhasePre, PhaseMain, PhasePost:
+			return p, nil
+		default:
+			return "", fmt.Errorf("invalid GOACTION_PHASE %q, expected %q, %q or %q", phase, PhasePre, PhaseMain, PhasePost)
+		}
+	}
+	switch filepath.Base(os.Args[0]) {
+	case PreExecutable:
+		return PhasePre, nil
+	case PostExecutable:
+		return PhasePost, nil
+	default:
+		return PhaseMain, nil
+	}
+}
+
+// SaveState saves a value that can be read by the following phases of the current action using
+// State. For example, a value saved in the main phase can be read in the post phase.
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#sending-values-to-the-pre-and-post-actions.
+func SaveState(name string, value string) error {
+	return current().SaveState(name, value)
+}
+
+// SaveState saves a value that can be read by the following phases of the current action using
+// State.
+func (c *Context) SaveState(name string, value string) error {
+	if !envName.MatchString(name) {
+		return fmt.Errorf("invalid state name %q", name)
+	}
+	if !c.CI {
+		return nil
+	}
+	if c.statePath == "" {
+		// Older runners don't support the state file, fallback to the deprecated command.
+		fmt.Fprintln(c.stdout, command.New("save-state", value, command.Property{Key: "name", Value: name}))
+		return nil
+	}
+	err := envfile.Append(c.statePath, name, value)
+	if err != nil {
+		return fmt.Errorf("failed writing to state file: %s", err)
+	}
+	return nil
+}
+
+// State returns a value that was saved with SaveState in a previous phase of the current action.
+func State(name string) string {
+	return current().State(name)
+}
+
+// State returns a value that was saved with SaveState in a previous phase of the current action.
+func (c *Context) State(name string) string {
+	return c.getenv("STATE_" + name)
+}
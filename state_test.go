@@ -0,0 +1,52 @@
+package goaction
+
+import (
+	"os"
+	"testing"
+
+	"github.com/posener/goaction/internal/envfile"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhase(t *testing.T) {
+	oldArgs := os.Args
+	defer func() { os.Args = oldArgs }()
+
+	tests := []struct {
+		exe  string
+		want ActionPhase
+	}{
+		{exe: "/bin/action", want: PhaseMain},
+		{exe: "/bin/action-pre", want: PhasePre},
+		{exe: "/bin/action-post", want: PhasePost},
+		{exe: "/bin/deploy-post", want: PhaseMain},
+		{exe: "/bin/action-pre-release", want: PhaseMain},
+	}
+	for _, tt := range tests {
+		os.Args = []string{tt.exe}
+		got, err := FromMap(nil).Phase()
+		require.NoError(t, err)
+		assert.Equal(t, tt.want, got)
+	}
+
+	got, err := FromMap(map[string]string{"GOACTION_PHASE": "post"}).Phase()
+	require.NoError(t, err)
+	assert.Equal(t, PhasePost, got)
+
+	_, err = FromMap(map[string]string{"GOACTION_PHASE": "postt"}).Phase()
+	assert.EqualError(t, err, `invalid GOACTION_PHASE "postt", expected "pre", "main" or "post"`)
+}
+
+func TestSaveState(t *testing.T) {
+	t.Parallel()
+
+	c, path, remove := testContext(t, "GITHUB_STATE", map[string]string{"STATE_pid": "1234"})
+	defer remove()
+
+	require.NoError(t, c.SaveState("pid", "1234"))
+	assert.Error(t, c.SaveState("invalid name", "value"))
+
+	assertEnvFile(t, readFile(t, path), envfile.Var{Name: "pid", Value: "1234"})
+	assert.Equal(t, "1234", c.State("pid"))
+}
@@ -0,0 +1,166 @@
+package goaction
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Summary builds a Markdown job summary that is displayed in the workflow run summary page.
+// Content is added with the builder methods and written with Write or Overwrite. When not running
+// in CI mode, the summary is written to the file in GITHUB_STEP_SUMMARY if it is set, or to stderr
+// otherwise.
+// See https://docs.github.com/en/actions/using-workflows/workflow-commands-for-github-actions#adding-a-job-summary.
+//
+//	err := goaction.NewSummary().
+//		Heading(2, "Results").
+//		Table([]string{"Test", "Result"}, []string{"foo", "pass"}).
+//		Write()
+type Summary struct {
+	b    strings.Builder
+	ci   bool
+	path string
+}
+
+// NewSummary returns a new, empty summary builder.
+func NewSummary() *Summary {
+	return current().NewSummary()
+}
+
+// NewSummary returns a new, empty summary builder for the job summary of the context.
+func (c *Context) NewSummary() *Summary {
+	return &Summary{ci: c.CI, path: c.summaryPath}
+}
+
+// Raw adds raw Markdown text to the summary.
+func (s *Summary) Raw(text string) *Summary {
+	s.b.WriteString(text)
+	return s
+}
+
+// Line adds a line of Markdown text to the summary.
+func (s *Summary) Line(text string) *Summary {
+	return s.Raw(text + "\n")
+}
+
+// Heading adds a heading in the given level (1-6) to the summary.
+func (s *Summary) Heading(level int, text string) *Summary {
+	if level < 1 {
+		level = 1
+	}
+	if level > 6 {
+		level = 6
+	}
+	return s.Line(strings.Repeat("#", level) + " " + text + "\n")
+}
+
+// Paragraph adds a paragraph of text to the summary.
+func (s *Summary) Paragraph(text string) *Summary {
+	return s.Line(text + "\n")
+}
+
+// CodeBlock adds a code block to the summary, with optional language for syntax highlighting.
+func (s *Summary) CodeBlock(code string, lang string) *Summary {
+	fence := "```"
+	// Make sure that the fence does not appear in the code itself.
+	for strings.Contains(code, fence) {
+		fence += "`"
+	}
+	return s.Line(fence + lang + "\n" + strings.TrimSuffix(code, "\n") + "\n" + fence + "\n")
+}
+
+// List adds a bullet list to the summary.
+func (s *Summary) List(items ...string) *Summary {
+	for _, item := range items {
+		s.Line("* " + item)
+	}
+	return s.Line("")
+}
+
+// Table adds a table with the given header and rows to the summary.
+func (s *Summary) Table(header []string, rows ...[]string) *Summary {
+	s.tableRow(header)
+	sep := make([]string, len(header))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	s.tableRow(sep)
+	for _, row := range rows {
+		s.tableRow(row)
+	}
+	return s.Line("")
+}
+
+func (s *Summary) tableRow(cells []string) {
+	escaped := make([]string, len(cells))
+	for i, cell := range cells {
+		cell = strings.ReplaceAll(cell, "|", "\\|")
+		cell = strings.ReplaceAll(cell, "\n", "<br>")
+		escaped[i] = cell
+	}
+	s.Line("| " + strings.Join(escaped, " | ") + " |")
+}
+
+// Details adds a collapsible section with the given label. The content is collapsed by default.
+func (s *Summary) Details(label string, content string) *Summary {
+	return s.Line("<details><summary>" + label + "</summary>\n\n" + content + "\n\n</details>\n")
+}
+
+// Link adds a link to the summary.
+func (s *Summary) Link(text string, url string) *Summary {
+	return s.Line(fmt.Sprintf("[%s](%s)\n", text, url))
+}
+
+// Image adds an image to the summary.
+func (s *Summary) Image(src string, alt string) *Summary {
+	return s.Line(fmt.Sprintf("![%s](%s)\n", alt, src))
+}
+
+// Separator adds a horizontal rule to the summary.
+func (s *Summary) Separator() *Summary {
+	return s.Line("---\n")
+}
+
+// String returns the Markdown content of the summary.
+func (s *Summary) String() string {
+	return s.b.String()
+}
+
+// Write appends the summary content to the job summary and empties the builder.
+func (s *Summary) Write() error {
+	return s.write(os.O_APPEND)
+}
+
+// Overwrite replaces the job summary with the summary content and empties the builder.
+func (s *Summary) Overwrite() error {
+	return s.write(os.O_TRUNC)
+}
+
+// Clear empties the builder and the job summary.
+func (s *Summary) Clear() error {
+	s.b.Reset()
+	return s.write(os.O_TRUNC)
+}
+
+func (s *Summary) write(mode int) error {
+	defer s.b.Reset()
+	if s.path == "" {
+		if s.ci {
+			return fmt.Errorf("job summary is not supported, GITHUB_STEP_SUMMARY is not set")
+		}
+		// Not in CI mode, and no local file was given.
+		_, err := io.WriteString(os.Stderr, s.b.String())
+		return err
+	}
+	f, err := os.OpenFile(s.path, mode|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("can't open summary file %s: %s", s.path, err)
+	}
+	defer f.Close()
+	_, err = io.WriteString(f, s.b.String())
+	if err != nil {
+		return fmt.Errorf("failed writing to summary file: %s", err)
+	}
+	return nil
+}
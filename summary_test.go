@@ -0,0 +1,54 @@
+package goaction
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	t.Parallel()
+
+	c, path, remove := testContext(t, "GITHUB_STEP_SUMMARY", nil)
+	defer remove()
+
+	s := c.NewSummary().
+		Heading(2, "Title").
+		Paragraph("Some text.").
+		Table([]string{"a", "b"}, []string{"1", "x|y"}, []string{"2", "multi\nline"}).
+		CodeBlock("fmt.Println(\"```\")", "go").
+		Details("More", "hidden").
+		List("one", "two").
+		Link("run", "https://github.com").
+		Image("https://github.com/image.png", "image")
+
+	want := "## Title\n\n" +
+		"Some text.\n\n" +
+		"| a | b |\n| --- | --- |\n| 1 | x\\|y |\n| 2 | multi<br>line |\n\n" +
+		"````go\nfmt.Println(\"```\")\n````\n\n" +
+		"<details><summary>More</summary>\n\nhidden\n\n</details>\n\n" +
+		"* one\n* two\n\n" +
+		"[run](https://github.com)\n\n" +
+		"![image](https://github.com/image.png)\n\n"
+	assert.Equal(t, want, s.String())
+
+	require.NoError(t, s.Write())
+	assert.Equal(t, "", s.String())
+	require.NoError(t, c.NewSummary().Line("appended").Write())
+	assert.Equal(t, want+"appended\n", readFile(t, path))
+
+	require.NoError(t, c.NewSummary().Line("overwritten").Overwrite())
+	assert.Equal(t, "overwritten\n", readFile(t, path))
+
+	require.NoError(t, c.NewSummary().Clear())
+	assert.Equal(t, "", readFile(t, path))
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	return string(b)
+}
@@ -0,0 +1,397 @@
+// Package testreport reports the results of `go test -json` in a Github action.
+//
+// The report can create error annotations at the file location of failing tests, add a results
+// table to the job summary, and set the test totals as action outputs.
+//
+//	r, err := testreport.Parse(os.Stdin, os.Stdout)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	r.Annotate()
+//	s := goaction.NewSummary()
+//	r.Summary(s, 10)
+//	err = s.Write()
+package testreport
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"go/token"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/posener/goaction"
+	"github.com/posener/goaction/annotate"
+)
+
+// Result of a test or a package.
+type Result string
+
+// Test results.
+const (
+	Pass Result = "pass"
+	Fail Result = "fail"
+	Skip Result = "skip"
+)
+
+// Report is the report of a go test run.
+type Report struct {
+	// Packages in the order of their first event.
+	Packages []*Package
+}
+
+// Package is the report of a tested package.
+type Package struct {
+	Name    string
+	Result  Result
+	Elapsed time.Duration
+	// Tests in the order that they started. Subtests are included.
+	Tests []*Test
+	// Output of the package that is not related to a specific test.
+	Output []string
+}
+
+// Test is the report of a single test.
+type Test struct {
+	Package string
+	Name    string
+	Result  Result
+	Elapsed time.Duration
+	// Output lines of the test.
+	Output []string
+}
+
+// event is a go test -json event. See `go doc test2json`.
+type event struct {
+	Action  string
+	Package string
+	Test    string
+	Elapsed float64
+	Output  string
+}
+
+// Parse parses the output of `go test -json`. The test output text is written to out, which can
+// be nil. Lines that are not JSON events, such as build errors, are also written to out.
+func Parse(r io.Reader, out io.Writer) (*Report, error) {
+	if out == nil {
+		out = ioutil.Discard
+	}
+	var (
+		report   Report
+		packages = map[string]*Package{}
+		tests    = map[string]*Test{}
+	)
+	pkg := func(name string) *Package {
+		p := packages[name]
+		if p == nil {
+			p = &Package{Name: name}
+			packages[name] = p
+			report.Packages = append(report.Packages, p)
+		}
+		return p
+	}
+	test := func(p *Package, name string) *Test {
+		key := p.Name + " " + name
+		t := tests[key]
+		if t == nil {
+			t = &Test{Package: p.Name, Name: name}
+			tests[key] = t
+			p.Tests = append(p.Tests, t)
+		}
+		return t
+	}
+
+	br := bufio.NewReader(r)
+	for {
+		line, err := br.ReadString('\n')
+		if len(line) > 0 {
+			var e event
+			if !strings.HasPrefix(line, "{") || json.Unmarshal([]byte(line), &e) != nil {
+				fmt.Fprint(out, line)
+			} else if e.Package == "" {
+				// Build events don't belong to a tested package.
+				fmt.Fprint(out, e.Output)
+			} else {
+				p := pkg(e.Package)
+				switch e.Action {
+				case "output":
+					fmt.Fprint(out, e.Output)
+					if e.Test == "" {
+						p.Output = append(p.Output, e.Output)
+					} else {
+						t := test(p, e.Test)
+						t.Output = append(t.Output, e.Output)
+					}
+				case "run":
+					test(p, e.Test)
+				case "pass", "fail", "skip":
+					elapsed := time.Duration(e.Elapsed * float64(time.Second))
+					if e.Test == "" {
+						p.Result, p.Elapsed = Result(e.Action), elapsed
+					} else {
+						t := test(p, e.Test)
+						t.Result, t.Elapsed = Result(e.Action), elapsed
+					}
+				}
+			}
+		}
+		if err == io.EOF {
+			return &report, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// Failed returns true if any package failed.
+func (r *Report) Failed() bool {
+	for _, p := range r.Packages {
+		if p.Result == Fail {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of tests with the given result.
+func (r *Report) Count(result Result) int {
+	n := 0
+	for _, p := range r.Packages {
+		n += p.Count(result)
+	}
+	return n
+}
+
+// Count returns the number of tests in the package with the given result.
+func (p *Package) Count(result Result) int {
+	n := 0
+	for _, t := range p.Tests {
+		if t.Result == result {
+			n++
+		}
+	}
+	return n
+}
+
+// A line in a test output that was logged with t.Error or t.Log.
+var reTestLog = regexp.MustCompile(`^(\s+)([^\s:]+\.go):(\d+): (.*)$`)
+
+// Annotate creates error annotations for the failing tests, at the file locations of their
+// t.Error and t.Fatal calls. Failing tests without such output, and packages that failed without
+// failing tests, are annotated without a file location.
+func (r *Report) Annotate() {
+	var diags []annotate.Diagnostic
+	for _, p := range r.Packages {
+		dir := packageDir(p.Name)
+		failed := false
+		for _, t := range p.Tests {
+			if t.Result != Fail {
+				continue
+			}
+			failed = true
+			diags = append(diags, t.diagnostics(dir, p.hasFailedSubtest(t))...)
+		}
+		if p.Result == Fail && !failed {
+			diags = append(diags, annotate.Diagnostic{
+				Title:   p.Name,
+				Message: failMessage("Package failed", p.Output),
+			})
+		}
+	}
+	annotate.Error(diags)
+}
+
+// diagnostics returns the diagnostics of a failed test. dir is the directory of the test package.
+// A test without file locations in its output is annotated only if none of its subtests failed.
+func (t *Test) diagnostics(dir string, failedSubtest bool) []annotate.Diagnostic {
+	var (
+		diags  []annotate.Diagnostic
+		indent string
+	)
+	for _, line := range t.Output {
+		line = strings.TrimRight(line, "\n")
+		if m := reTestLog.FindStringSubmatch(line); m != nil {
+			lineNum, _ := strconv.Atoi(m[3])
+			indent = m[1]
+			diags = append(diags, annotate.Diagnostic{
+				Title:   t.Name,
+				Start:   token.Position{Filename: filepath.Join(dir, m[2]), Line: lineNum},
+				Message: m[4],
+			})
+			continue
+		}
+		// Continuation lines of a multi-line message are indented more than the message.
+		if len(diags) > 0 && indent != "" && strings.HasPrefix(line, indent+" ") {
+			diags[len(diags)-1].Message += "\n" + strings.TrimSpace(line)
+			continue
+		}
+		indent = ""
+	}
+	if len(diags) == 0 && !failedSubtest {
+		diags = append(diags, annotate.Diagnostic{
+			Title:   t.Name,
+			Message: failMessage("Test failed", t.Output),
+		})
+	}
+	return diags
+}
+
+// failMessage returns a failure message with the output that explains it.
+func failMessage(msg string, output []string) string {
+	out := strings.TrimSpace(strings.Join(output, ""))
+	if out == "" {
+		return msg + "."
+	}
+	return msg + ":\n" + out
+}
+
+// hasFailedSubtest returns true if one of the subtests of the test failed. Such failures are
+// annotated in the subtest.
+func (p *Package) hasFailedSubtest(t *Test) bool {
+	for _, sub := range p.Tests {
+		if sub.Result == Fail && strings.HasPrefix(sub.Name, t.Name+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// packageDir returns the directory of a package, according to the Go module in the working
+// directory. If it can't be found, the working directory is returned.
+func packageDir(pkg string) string {
+	dir, err := filepath.Abs(".")
+	if err != nil {
+		return "."
+	}
+	for {
+		b, err := ioutil.ReadFile(filepath.Join(dir, "go.mod"))
+		if err == nil {
+			module := modulePath(string(b))
+			if pkg == module {
+				return dir
+			}
+			if module != "" && strings.HasPrefix(pkg, module+"/") {
+				return filepath.Join(dir, filepath.FromSlash(strings.TrimPrefix(pkg, module+"/")))
+			}
+			return "."
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "."
+		}
+		dir = parent
+	}
+}
+
+// modulePath returns the module path from the content of a go.mod file.
+func modulePath(gomod string) string {
+	for _, line := range strings.Split(gomod, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) >= 2 && fields[0] == "module" {
+			return strings.Trim(fields[1], `"`)
+		}
+	}
+	return ""
+}
+
+// Summary adds the report to a job summary: a table of the packages with their results, the
+// failed tests and a table of the slowest tests. slowest is the maximal number of slowest tests to
+// show.
+func (r *Report) Summary(s *goaction.Summary, slowest int) *goaction.Summary {
+	status := "✅ Tests passed"
+	if r.Failed() {
+		status = "❌ Tests failed"
+	}
+	s.Heading(2, status)
+	s.Paragraph(fmt.Sprintf("%d passed, %d failed, %d skipped.", r.Count(Pass), r.Count(Fail), r.Count(Skip)))
+
+	var rows [][]string
+	for _, p := range r.Packages {
+		rows = append(rows, []string{
+			resultIcon(p.Result) + " " + p.Name,
+			strconv.Itoa(p.Count(Pass)),
+			strconv.Itoa(p.Count(Fail)),
+			strconv.Itoa(p.Count(Skip)),
+			formatDuration(p.Elapsed),
+		})
+	}
+	s.Table([]string{"Package", "Passed", "Failed", "Skipped", "Time"}, rows...)
+
+	var failed []string
+	for _, t := range r.tests() {
+		if t.Result == Fail {
+			failed = append(failed, fmt.Sprintf("`%s` in %s", t.Name, t.Package))
+		}
+	}
+	if len(failed) > 0 {
+		s.Heading(3, "Failed tests")
+		s.List(failed...)
+	}
+
+	tests := r.tests()
+	sort.SliceStable(tests, func(i, j int) bool { return tests[i].Elapsed > tests[j].Elapsed })
+	rows = nil
+	for _, t := range tests {
+		if len(rows) >= slowest || t.Elapsed == 0 {
+			break
+		}
+		rows = append(rows, []string{t.Name, t.Package, formatDuration(t.Elapsed)})
+	}
+	if len(rows) > 0 {
+		s.Heading(3, "Slowest tests")
+		s.Table([]string{"Test", "Package", "Time"}, rows...)
+	}
+	return s
+}
+
+// Output sets the test totals as the action outputs "passed", "failed" and "skipped".
+func (r *Report) Output() error {
+	for _, o := range []struct {
+		name   string
+		result Result
+	}{
+		{name: "passed", result: Pass},
+		{name: "failed", result: Fail},
+		{name: "skipped", result: Skip},
+	} {
+		err := goaction.Output(o.name, strconv.Itoa(r.Count(o.result)), "Number of "+o.name+" tests.")
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// tests returns all the tests in the report.
+func (r *Report) tests() []*Test {
+	var tests []*Test
+	for _, p := range r.Packages {
+		tests = append(tests, p.Tests...)
+	}
+	return tests
+}
+
+func resultIcon(r Result) string {
+	switch r {
+	case Pass:
+		return "✅"
+	case Fail:
+		return "❌"
+	case Skip:
+		return "⏭️"
+	default:
+		return "❔"
+	}
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(time.Millisecond).String()
+}
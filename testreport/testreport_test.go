@@ -0,0 +1,100 @@
+package testreport
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/posener/goaction"
+	"github.com/posener/goaction/goactiontest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const pkg = "github.com/posener/goaction/testreport"
+
+func parseTestdata(t *testing.T) *Report {
+	t.Helper()
+	f, err := os.Open("testdata/test.json")
+	require.NoError(t, err)
+	defer f.Close()
+	var out bytes.Buffer
+	r, err := Parse(f, &out)
+	require.NoError(t, err)
+	assert.Contains(t, out.String(), "build/main.go:3:1: syntax error\n")
+	assert.Contains(t, out.String(), "    a_test.go:11: line1\n        line2\n")
+	return r
+}
+
+func TestParse(t *testing.T) {
+	r := parseTestdata(t)
+
+	require.Len(t, r.Packages, 3)
+	p := r.Packages[0]
+	assert.Equal(t, pkg, p.Name)
+	assert.Equal(t, Fail, p.Result)
+	assert.Equal(t, 24*time.Millisecond, p.Elapsed)
+	require.Len(t, p.Tests, 4)
+	assert.Equal(t, "TestPass", p.Tests[0].Name)
+	assert.Equal(t, Pass, p.Tests[0].Result)
+	assert.Equal(t, 20*time.Millisecond, p.Tests[0].Elapsed)
+	assert.Equal(t, "TestFail/sub", p.Tests[2].Name)
+	assert.Equal(t, Fail, p.Tests[2].Result)
+	assert.Equal(t, []string{"=== RUN   TestFail/sub\n", "    a_test.go:13: sub failed\n", "--- FAIL: TestFail/sub (0.00s)\n"}, p.Tests[2].Output)
+
+	assert.Equal(t, Pass, r.Packages[1].Result)
+	assert.Equal(t, Fail, r.Packages[2].Result)
+
+	assert.True(t, r.Failed())
+	assert.Equal(t, 2, r.Count(Pass))
+	assert.Equal(t, 2, r.Count(Fail))
+	assert.Equal(t, 1, r.Count(Skip))
+}
+
+func TestAnnotate(t *testing.T) {
+	workspace, err := filepath.Abs("..")
+	require.NoError(t, err)
+	env := goactiontest.New(t, goactiontest.WithEnv("GITHUB_WORKSPACE", workspace))
+	defer env.Close()
+
+	r := parseTestdata(t)
+	r.Annotate()
+
+	assert.Equal(t, []goactiontest.Annotation{
+		{Level: "error", Title: "TestFail", File: "testreport/a_test.go", Line: 11, Message: "line1\nline2"},
+		{Level: "error", Title: "TestFail/sub", File: "testreport/a_test.go", Line: 13, Message: "sub failed"},
+		{Level: "error", Title: pkg + "/build", Message: "Package failed."},
+	}, env.Annotations())
+}
+
+func TestSummary(t *testing.T) {
+	r := parseTestdata(t)
+
+	got := r.Summary(&goaction.Summary{}, 1).String()
+	want := "## ❌ Tests failed\n\n" +
+		"2 passed, 2 failed, 1 skipped.\n\n" +
+		"| Package | Passed | Failed | Skipped | Time |\n" +
+		"| --- | --- | --- | --- | --- |\n" +
+		"| ❌ " + pkg + " | 1 | 2 | 1 | 24ms |\n" +
+		"| ✅ " + pkg + "/sub | 1 | 0 | 0 | 4ms |\n" +
+		"| ❌ " + pkg + "/build | 0 | 0 | 0 | 0s |\n\n" +
+		"### Failed tests\n\n" +
+		"* `TestFail` in " + pkg + "\n" +
+		"* `TestFail/sub` in " + pkg + "\n\n" +
+		"### Slowest tests\n\n" +
+		"| Test | Package | Time |\n" +
+		"| --- | --- | --- |\n" +
+		"| TestPass | " + pkg + " | 20ms |\n\n"
+	assert.Equal(t, want, got)
+}
+
+func TestOutput(t *testing.T) {
+	env := goactiontest.New(t)
+	defer env.Close()
+
+	r := parseTestdata(t)
+	require.NoError(t, r.Output())
+	assert.Equal(t, map[string]string{"passed": "2", "failed": "2", "skipped": "1"}, env.Outputs())
+}